@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/text"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/newrelic/newrelic-cli/internal/audit"
 	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/config"
 	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/output"
 	"github.com/newrelic/newrelic-cli/internal/utils"
@@ -21,6 +24,17 @@ import (
 
 var outputFormat string
 var outputPlain bool
+var outputFilter string
+var outputTemplate string
+var outputTemplateFile string
+var outputColumns []string
+var outputSortBy string
+var outputFile string
+var outputAppend bool
+var profileName string
+var retryMaxAttempts int
+var retryMaxBackoff time.Duration
+var dryRun bool
 
 const defaultProfileName string = "default"
 
@@ -35,6 +49,7 @@ var Command = &cobra.Command{
 }
 
 func initializeCLI(cmd *cobra.Command, args []string) {
+	audit.SetCommand(cmd, args)
 	initializeProfile()
 }
 
@@ -149,13 +164,19 @@ func fetchLicenseKey(client *newrelic.NewRelic, accountID int) (string, error) {
 	}
 
 	queryResp := resp.(nerdgraph.QueryResponse)
-	actor := queryResp.Actor.(map[string]interface{})
-	account := actor["account"].(map[string]interface{})
-	licenseKey := account["licenseKey"].(string)
+	actor, _ := queryResp.Actor.(map[string]interface{})
+	account, _ := actor["account"].(map[string]interface{})
+
+	licenseKey, ok := account["licenseKey"].(string)
+	if !ok {
+		return "", fmt.Errorf("no license key found for account %d", accountID)
+	}
 
 	return licenseKey, nil
 }
 
+var errMultipleAccounts = errors.New("multiple accounts found, please set NEW_RELIC_ACCOUNT_ID")
+
 // fetchAccountID will try and retrieve an account ID for the given user.  If it
 // finds more than one account it will returrn an error.
 func fetchAccountID(client *newrelic.NewRelic) (int, error) {
@@ -172,7 +193,7 @@ func fetchAccountID(client *newrelic.NewRelic) (int, error) {
 		return accounts[0].ID, nil
 	}
 
-	return 0, errors.New("multiple accounts found, please set NEW_RELIC_ACCOUNT_ID")
+	return 0, errMultipleAccounts
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -186,17 +207,74 @@ func Execute() error {
 	// since we have a custom error handler in main.go
 	Command.SilenceErrors = true
 
-	return Command.Execute()
+	// Requests stopped by --dry-run are reported rather than treated as errors
+	if err := Command.Execute(); err != nil && !errors.Is(err, client.ErrDryRun) {
+		output.Discard()
+		return err
+	}
+
+	if err := client.ReportDryRun(); err != nil {
+		output.Discard()
+		return err
+	}
+
+	return output.Flush()
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	Command.PersistentFlags().StringVar(&profileName, "profile", "", "the authentication profile to use for this command, overrides NEW_RELIC_PROFILE and the default profile")
 	Command.PersistentFlags().StringVar(&outputFormat, "format", output.DefaultFormat.String(), "output text format ["+output.FormatOptions()+"]")
 	Command.PersistentFlags().BoolVar(&outputPlain, "plain", false, "output compact text")
+	Command.PersistentFlags().StringVar(&outputFilter, "filter", "", "filter the result with a GJSON path expression before formatting, e.g. '#.name'")
+	Command.PersistentFlags().StringVar(&outputTemplate, "template", "", "a Go template used to render the result, implies --format Template")
+	Command.PersistentFlags().StringVar(&outputTemplateFile, "template-file", "", "a file containing a Go template used to render the result, implies --format Template")
+	Command.PersistentFlags().StringSliceVar(&outputColumns, "columns", []string{}, "the columns to include in Text, CSV and TSV output, e.g. name,guid")
+	Command.PersistentFlags().StringVar(&outputSortBy, "sort-by", "", "the column to sort Text, CSV and TSV output by, prefix with '-' to sort descending")
+	Command.PersistentFlags().StringVar(&outputFile, "output-file", "", "write the result to a file, replacing it atomically, or '-' for stdout")
+	Command.PersistentFlags().BoolVar(&outputAppend, "output-append", false, "append to the --output-file instead of replacing it, NDJSON format only")
+	Command.PersistentFlags().IntVar(&retryMaxAttempts, "retry-max-attempts", 0, "the number of attempts for rate limited or failed requests, 1 disables retries, overrides http.retryMaxAttempts")
+	Command.PersistentFlags().DurationVar(&retryMaxBackoff, "retry-max-backoff", 0, "the longest wait between retries, e.g. 10s, overrides http.retryMaxBackoff")
+	Command.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "show the mutation or request that would change data, with secrets redacted, instead of sending it")
 }
 
 func initConfig() {
-	utils.LogIfError(output.SetFormat(output.ParseFormat(outputFormat)))
+	credentials.SetProfileOverride(profileName)
+	client.SetRetryOverrides(retryMaxAttempts, retryMaxBackoff)
+	client.SetDryRun(dryRun)
+
+	// Apply the defaults from the global and project configuration
+	config.WithConfig(func(cfg *config.Config) {
+		credentials.SetConfigOverrides(cfg.Profile, cfg.AccountID)
+
+		if cfg.Output.Format != "" && !Command.PersistentFlags().Changed("format") {
+			outputFormat = cfg.Output.Format
+		}
+
+		if cfg.Output.Plain && !Command.PersistentFlags().Changed("plain") {
+			outputPlain = true
+		}
+	})
+
+	format := output.ParseFormat(outputFormat)
+
+	if outputTemplate != "" || outputTemplateFile != "" {
+		if outputTemplateFile != "" {
+			utils.LogIfFatal(output.SetTemplateFile(outputTemplateFile))
+		} else {
+			utils.LogIfFatal(output.SetTemplate(outputTemplate))
+		}
+
+		if !Command.PersistentFlags().Changed("format") {
+			format = output.FormatTemplate
+		}
+	}
+
+	utils.LogIfError(output.SetFormat(format))
 	utils.LogIfError(output.SetPrettyPrint(!outputPlain))
+	utils.LogIfError(output.SetFilter(outputFilter))
+	utils.LogIfError(output.SetColumns(outputColumns))
+	utils.LogIfError(output.SetSortBy(outputSortBy))
+	utils.LogIfFatal(output.SetOutputFile(outputFile, outputAppend))
 }
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+	"github.com/newrelic/newrelic-client-go/pkg/region"
+)
+
+const (
+	checkOK      = "ok"
+	checkFailed  = "failed"
+	checkMissing = "missing"
+	checkSkipped = "skipped"
+)
+
+// profileCheck is the result of validating a single profile setting
+type profileCheck struct {
+	Check  string `json:"check" yaml:"check"`
+	Status string `json:"status" yaml:"status"`
+	Detail string `json:"detail,omitempty" yaml:"detail,omitempty"`
+}
+
+// profileValidation is the result of validating a profile
+type profileValidation struct {
+	Profile string         `json:"profile" yaml:"profile"`
+	Valid   bool           `json:"valid" yaml:"valid"`
+	Checks  []profileCheck `json:"checks" yaml:"checks"`
+}
+
+var cmdProfileValidate = &cobra.Command{
+	Use:   "validate [name]",
+	Short: "Check that a profile's keys, account and region work",
+	Long: `Check that a profile's keys, account and region work
+
+The validate command checks the named profile, or the profile in use when no
+name is given, against NerdGraph.  It reports whether the API key is accepted,
+whether the region is correct, whether the account ID is accessible and whether
+the license key belongs to that account.  The command exits with an error when
+any check fails, so it can be used as a preflight check in CI.
+`,
+	Example: "newrelic profile validate <profileName> --format JSON",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config.WithConfig(func(cfg *config.Config) {
+			credentials.WithCredentials(func(creds *credentials.Credentials) {
+				var (
+					name string
+					p    *credentials.Profile
+					err  error
+				)
+
+				if len(args) > 0 {
+					name = args[0]
+					p, err = creds.GetProfile(name)
+				} else {
+					name = creds.ActiveProfileName()
+					p, err = creds.ActiveProfile()
+				}
+
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				if p == nil {
+					log.Fatal("no profile found, see newrelic profile add --help")
+				}
+
+				result := validateProfile(cfg, name, p)
+
+				utils.LogIfFatal(output.Print(result))
+
+				if !result.Valid {
+					log.Fatalf("profile %s failed validation", name)
+				}
+			})
+		})
+	},
+}
+
+// validateProfile checks each of the profile's settings against NerdGraph
+func validateProfile(cfg *config.Config, name string, p *credentials.Profile) *profileValidation {
+	result := &profileValidation{
+		Profile: name,
+		Valid:   true,
+	}
+
+	// Not every profile needs these keys, so they only fail when incorrect
+	optional := map[string]bool{"insightsInsertKey": true, "licenseKey": true}
+
+	add := func(check string, status string, detail string) {
+		if status == checkFailed || (status == checkMissing && !optional[check]) {
+			result.Valid = false
+		}
+
+		result.Checks = append(result.Checks, profileCheck{Check: check, Status: status, Detail: detail})
+	}
+
+	if p.APIKey == "" {
+		add("apiKey", checkMissing, "no API key configured")
+		return result
+	}
+
+	nrClient, regionName, err := findRegion(cfg, p)
+	if err != nil {
+		add("apiKey", checkFailed, err.Error())
+		add("region", checkSkipped, "the API key is not valid in any region")
+		return result
+	}
+
+	add("apiKey", checkOK, "")
+
+	configured := strings.ToUpper(p.Region)
+	if configured == "" {
+		configured = region.Default.String()
+	}
+
+	if regionName == configured {
+		add("region", checkOK, regionName)
+	} else {
+		add("region", checkFailed, fmt.Sprintf("the API key belongs to the %s region, not %s", regionName, configured))
+	}
+
+	validateAccount(nrClient, p, add)
+
+	if p.InsightsInsertKey == "" {
+		add("insightsInsertKey", checkMissing, "no Insights insert key configured")
+	} else {
+		add("insightsInsertKey", checkSkipped, "cannot be verified without sending an event")
+	}
+
+	return result
+}
+
+// validateAccount checks the account ID is accessible and the license key
+// belongs to it
+func validateAccount(nrClient *newrelic.NewRelic, p *credentials.Profile, add func(string, string, string)) {
+	if p.AccountID == 0 {
+		add("accountID", checkMissing, "no account ID configured")
+		add("licenseKey", checkSkipped, "requires an account ID")
+		return
+	}
+
+	licenseKey, err := fetchLicenseKey(nrClient, p.AccountID)
+	if err != nil {
+		add("accountID", checkFailed, fmt.Sprintf("account %d is not accessible: %s", p.AccountID, err))
+		add("licenseKey", checkSkipped, "requires an accessible account")
+		return
+	}
+
+	add("accountID", checkOK, fmt.Sprint(p.AccountID))
+
+	switch p.LicenseKey {
+	case "":
+		add("licenseKey", checkMissing, "no license key configured")
+	case licenseKey:
+		add("licenseKey", checkOK, "")
+	default:
+		add("licenseKey", checkFailed, fmt.Sprintf("the license key does not belong to account %d", p.AccountID))
+	}
+}
+
+// findRegion returns a client for the first region the API key is accepted
+// in, trying the profile's region first.
+func findRegion(cfg *config.Config, p *credentials.Profile) (*newrelic.NewRelic, string, error) {
+	regions := []string{strings.ToUpper(p.Region)}
+	for _, r := range []region.Name{region.US, region.EU} {
+		if r.String() != regions[0] {
+			regions = append(regions, r.String())
+		}
+	}
+
+	var firstErr error
+
+	for _, r := range regions {
+		if r == "" {
+			continue
+		}
+
+		nrClient, err := client.CreateNRClientForProfile(cfg, p, r)
+		if err == nil {
+			_, err = fetchAccountID(nrClient)
+			if err == nil || err == errMultipleAccounts {
+				return nrClient, r, nil
+			}
+		}
+
+		log.Debugf("API key check failed in region %s: %s", r, err)
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return nil, "", firstErr
+}
+
+func init() {
+	credentials.Command.AddCommand(cmdProfileValidate)
+}
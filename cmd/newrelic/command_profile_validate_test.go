@@ -0,0 +1,99 @@
+// +build unit
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+)
+
+func TestProfileValidateCommand(t *testing.T) {
+	assert.Equal(t, "validate", cmdProfileValidate.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdProfileValidate)
+	testcobra.CheckCobraRequiredFlags(t, cmdProfileValidate, []string{})
+	testcobra.CheckCobraCommandAliases(t, cmdProfileValidate, []string{})
+}
+
+func TestValidateProfile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		switch {
+		case r.Header.Get("Api-Key") != "goodAPIKey":
+			fmt.Fprint(w, `{"errors": [{"message": "Invalid API key"}]}`)
+		case strings.Contains(string(body), "licenseKey") && strings.Contains(string(body), `"accountId":1`):
+			fmt.Fprint(w, `{"data": {"actor": {"account": {"licenseKey": "goodLicenseKey"}}}}`)
+		case strings.Contains(string(body), "licenseKey"):
+			fmt.Fprint(w, `{"data": {"actor": {"account": null}}, "errors": [{"message": "Account not found"}]}`)
+		default:
+			fmt.Fprint(w, `{"data": {"actor": {"accounts": [{"id": 1, "name": "Account"}]}}}`)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv("NEW_RELIC_NERDGRAPH_URL", server.URL)
+	defer os.Unsetenv("NEW_RELIC_NERDGRAPH_URL")
+
+	cfg := &config.Config{LogLevel: config.DefaultLogLevel}
+
+	statuses := func(v *profileValidation) map[string]string {
+		m := map[string]string{}
+		for _, c := range v.Checks {
+			m[c.Check] = c.Status
+		}
+		return m
+	}
+
+	result := validateProfile(cfg, "good", &credentials.Profile{
+		APIKey:     "goodAPIKey",
+		Region:     "US",
+		AccountID:  1,
+		LicenseKey: "goodLicenseKey",
+	})
+	assert.True(t, result.Valid)
+	assert.Equal(t, map[string]string{
+		"apiKey":            checkOK,
+		"region":            checkOK,
+		"accountID":         checkOK,
+		"licenseKey":        checkOK,
+		"insightsInsertKey": checkMissing,
+	}, statuses(result))
+
+	result = validateProfile(cfg, "wrongLicense", &credentials.Profile{
+		APIKey:     "goodAPIKey",
+		Region:     "US",
+		AccountID:  1,
+		LicenseKey: "otherLicenseKey",
+	})
+	assert.False(t, result.Valid)
+	assert.Equal(t, checkFailed, statuses(result)["licenseKey"])
+
+	result = validateProfile(cfg, "wrongAccount", &credentials.Profile{
+		APIKey:    "goodAPIKey",
+		Region:    "US",
+		AccountID: 2,
+	})
+	assert.False(t, result.Valid)
+	assert.Equal(t, checkFailed, statuses(result)["accountID"])
+
+	result = validateProfile(cfg, "badKey", &credentials.Profile{
+		APIKey:    "badAPIKey",
+		Region:    "US",
+		AccountID: 1,
+	})
+	require.False(t, result.Valid)
+	assert.Equal(t, checkFailed, statuses(result)["apiKey"])
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/agent"
 	"github.com/newrelic/newrelic-cli/internal/apiaccess"
 	"github.com/newrelic/newrelic-cli/internal/apm"
+	"github.com/newrelic/newrelic-cli/internal/audit"
 	"github.com/newrelic/newrelic-cli/internal/config"
 	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/decode"
@@ -21,6 +22,7 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/nerdstorage"
 	"github.com/newrelic/newrelic-cli/internal/nrql"
 	"github.com/newrelic/newrelic-cli/internal/reporting"
+	"github.com/newrelic/newrelic-cli/internal/undo"
 	"github.com/newrelic/newrelic-cli/internal/workload"
 )
 
@@ -48,6 +50,8 @@ func init() {
 	Command.AddCommand(install.Command)
 	Command.AddCommand(install.TestCommand)
 	Command.AddCommand(apiaccess.Command)
+	Command.AddCommand(audit.Command)
+	Command.AddCommand(undo.Command)
 
 	CheckPrereleaseMode(Command)
 }
@@ -2,11 +2,12 @@ package apm
 
 import (
 	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/credentials"
 )
 
 var (
-	apmAccountID string
-	apmAppID     int
+	apmAppID int
 )
 
 // Command represents the apm command
@@ -17,6 +18,6 @@ var Command = &cobra.Command{
 
 func init() {
 	// Flags for all things APM
-	Command.PersistentFlags().StringVarP(&apmAccountID, "accountId", "a", "", "A New Relic account ID")
+	credentials.AddAccountIDFlag(Command.PersistentFlags(), "A New Relic account ID")
 	Command.PersistentFlags().IntVarP(&apmAppID, "applicationId", "", 0, "A New Relic APM application ID")
 }
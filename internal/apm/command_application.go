@@ -1,6 +1,8 @@
 package apm
 
 import (
+	"strconv"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -8,6 +10,7 @@ import (
 	"github.com/newrelic/newrelic-client-go/pkg/entities"
 
 	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/output"
 	"github.com/newrelic/newrelic-cli/internal/utils"
 )
@@ -34,8 +37,13 @@ The search command performs a query for an APM application name and/or account I
 `,
 	Example: "newrelic apm application search --name <appName>",
 	Run: func(cmd *cobra.Command, args []string) {
+		// The account only narrows the search when it's asked for
+		var accountID int
+		if cmd.Flags().Changed(credentials.AccountIDFlagName) {
+			accountID = credentials.AccountID()
+		}
 
-		if appGUID == "" && appName == "" && apmAccountID == "" {
+		if appGUID == "" && appName == "" && accountID == 0 {
 			utils.LogIfError(cmd.Help())
 			log.Fatal("one of --accountId, --guid, --name are required")
 		}
@@ -46,7 +54,7 @@ The search command performs a query for an APM application name and/or account I
 
 			// Look for just the GUID if passed in
 			if appGUID != "" {
-				if appName != "" || apmAccountID != "" {
+				if appName != "" || cmd.Flags().Changed(credentials.AccountIDFlagName) {
 					log.Warnf("Searching for --guid only, ignoring --accountId and --name")
 				}
 
@@ -64,8 +72,8 @@ The search command performs a query for an APM application name and/or account I
 					params.Name = appName
 				}
 
-				if apmAccountID != "" {
-					params.Tags = []entities.EntitySearchQueryBuilderTag{{Key: "accountId", Value: apmAccountID}}
+				if accountID != 0 {
+					params.Tags = []entities.EntitySearchQueryBuilderTag{{Key: "accountId", Value: strconv.Itoa(accountID)}}
 				}
 
 				results, err := nrClient.Entities.GetEntitySearch(
@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Results of an audited operation
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+var (
+	// The command being run, see SetCommand
+	command string
+	args    []string
+
+	// Flags whose values are never written to the log
+	secretFlagsRegexp = regexp.MustCompile(`(?i)(apikey|insertkey|licensekey|passphrase|password|secret|token)`)
+
+	mu sync.Mutex
+)
+
+// Entry records an operation which changed data in New Relic
+type Entry struct {
+	Time      time.Time `json:"time" yaml:"time"`
+	User      string    `json:"user,omitempty" yaml:"user,omitempty"`
+	Profile   string    `json:"profile,omitempty" yaml:"profile,omitempty"`
+	AccountID int       `json:"accountId,omitempty" yaml:"accountId,omitempty"`
+	Command   string    `json:"command" yaml:"command"`
+	Args      []string  `json:"args,omitempty" yaml:"args,omitempty"`
+	Operation string    `json:"operation" yaml:"operation"`
+	Targets   []string  `json:"targets,omitempty" yaml:"targets,omitempty"`
+	Result    string    `json:"result" yaml:"result"`
+	Error     string    `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// SetCommand records the command being run and its arguments, for the
+// entries written while it runs.  The values of secret flags are redacted.
+func SetCommand(cmd *cobra.Command, positional []string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	command = cmd.CommandPath()
+	args = append([]string{}, positional...)
+
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		value := f.Value.String()
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			value = strings.Join(s.GetSlice(), ",")
+		}
+
+		if secretFlagsRegexp.MatchString(f.Name) {
+			value = "REDACTED"
+		}
+
+		args = append(args, fmt.Sprintf("--%s=%s", f.Name, value))
+	})
+}
+
+// NewEntry returns an entry for the command being run
+func NewEntry() *Entry {
+	mu.Lock()
+	defer mu.Unlock()
+
+	e := &Entry{
+		Time:    time.Now().UTC(),
+		Command: command,
+		Args:    append([]string{}, args...),
+	}
+
+	if u, err := user.Current(); err == nil {
+		e.User = u.Username
+	}
+
+	return e
+}
+
+// Write appends an entry to the log file.  Entries are only ever added.
+func Write(path string, e *Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err = os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		f.Close() // nolint:errcheck
+		return err
+	}
+
+	return f.Close()
+}
+
+// Read returns the entries in the log file written at or after since.  A
+// missing log file has no entries.
+func Read(path string, since time.Time) ([]Entry, error) {
+	entries := []Entry{}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("error reading audit log %s line %d: %s", path, line, err)
+		}
+
+		if !e.Time.Before(since) {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, scanner.Err()
+}
@@ -0,0 +1,81 @@
+// +build unit
+
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+)
+
+func TestAuditCommand(t *testing.T) {
+	assert.Equal(t, "audit", Command.Name())
+
+	testcobra.CheckCobraMetadata(t, Command)
+	testcobra.CheckCobraRequiredFlags(t, Command, []string{})
+}
+
+func TestSetCommand(t *testing.T) {
+	root := &cobra.Command{Use: "newrelic"}
+	cmd := &cobra.Command{Use: "add"}
+	root.AddCommand(cmd)
+
+	cmd.Flags().String("name", "", "")
+	cmd.Flags().String("apiKey", "", "")
+	cmd.Flags().StringSlice("tag", []string{}, "")
+	cmd.Flags().Int("unused", 0, "")
+	require.NoError(t, cmd.Flags().Parse([]string{"--name", "prod", "--apiKey", "NRAK-SECRET", "--tag", "a:1,b:2"}))
+
+	SetCommand(cmd, []string{"positional"})
+	defer SetCommand(root, nil)
+
+	e := NewEntry()
+	assert.Equal(t, "newrelic add", e.Command)
+	assert.Equal(t, []string{"positional", "--apiKey=REDACTED", "--name=prod", "--tag=a:1,b:2"}, e.Args)
+	assert.WithinDuration(t, time.Now(), e.Time, time.Minute)
+}
+
+func TestWriteRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "logs", "audit.jsonl")
+
+	// A missing log has no entries
+	entries, err := Read(path, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	now := time.Now().UTC()
+	require.NoError(t, Write(path, &Entry{Time: now.Add(-48 * time.Hour), Operation: "old", Result: ResultSuccess}))
+	require.NoError(t, Write(path, &Entry{Time: now, Operation: "new", Targets: []string{"MTIz"}, Result: ResultError, Error: "failed"}))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err = Read(path, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "old", entries[0].Operation)
+
+	entries, err = Read(path, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "new", entries[0].Operation)
+	assert.Equal(t, []string{"MTIz"}, entries[0].Targets)
+	assert.Equal(t, "failed", entries[0].Error)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("not json\n"), 0600))
+	_, err = Read(path, time.Time{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+var (
+	since time.Duration
+)
+
+// Command represents the audit command
+var Command = &cobra.Command{
+	Use:   "audit",
+	Short: "View the log of changes made with the New Relic CLI",
+}
+
+var cmdList = &cobra.Command{
+	Use:   "list",
+	Short: "List the changes made with the New Relic CLI",
+	Long: `List the changes made with the New Relic CLI
+
+Every mutation sent to New Relic, such as creating a tag or deleting a key, is
+appended to the audit log with the user, profile, account, command, targets and
+result.  Secrets are redacted.  The log is kept in the file named by the
+auditLogFile configuration key.
+`,
+	Example: `newrelic audit list --since 24h
+newrelic audit list --since 168h --format Text --columns time,command,targets,result`,
+	Run: func(cmd *cobra.Command, args []string) {
+		config.WithConfig(func(cfg *config.Config) {
+			var from time.Time
+			if since > 0 {
+				from = time.Now().Add(-since)
+			}
+
+			entries, err := Read(cfg.AuditLogFile, from)
+			utils.LogIfFatal(err)
+
+			utils.LogIfFatal(output.Print(entries))
+		})
+	},
+}
+
+func init() {
+	Command.AddCommand(cmdList)
+	cmdList.Flags().DurationVar(&since, "since", 0, "only list changes made within this long, e.g. 24h")
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/audit"
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+)
+
+var (
+	// The first field of a mutation names the operation
+	mutationFieldRegexp = regexp.MustCompile(`^mutation[^{]*\{\s*(\w+)`)
+
+	// Variables which identify the entities, keys and other things changed
+	targetVariableRegexp = regexp.MustCompile(`(?i)(guid|id)s?$`)
+)
+
+// auditTransport appends each GraphQL mutation, and each REST request which
+// isn't a read, to the audit log along with its result.  It wraps the retry
+// transport, so a retried request is logged once with its final result.
+type auditTransport struct {
+	path      string
+	profile   string
+	accountID int
+	scrubber  *scrubber
+	next      http.RoundTripper
+}
+
+// newAuditTransport returns a transport logging the changes made through
+// base to the audit log in the config.
+func newAuditTransport(cfg *config.Config, profileName string, p *credentials.Profile, base *http.Transport) *http.Transport {
+	t := &auditTransport{
+		path:     cfg.AuditLogFile,
+		profile:  profileName,
+		scrubber: &scrubber{},
+		next:     base,
+	}
+
+	if p != nil {
+		t.accountID = p.AccountID
+		t.scrubber.addSecrets(p.APIKey, p.InsightsInsertKey, p.LicenseKey)
+	}
+
+	return wrapTransport(t)
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close() // nolint:errcheck
+	}
+
+	r := req.Clone(req.Context())
+	if body != nil {
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	e := t.entry(req, body)
+	if e == nil {
+		return t.next.RoundTrip(r)
+	}
+
+	resp, err := t.next.RoundTrip(r)
+	if errors.Is(err, ErrDryRun) {
+		// Nothing was changed
+		return resp, err
+	}
+
+	if err != nil {
+		e.Result = audit.ResultError
+		e.Error = err.Error()
+	} else {
+		respBody, readErr := ioutil.ReadAll(resp.Body)
+		resp.Body.Close() // nolint:errcheck
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+		e.Result, e.Error = auditResult(resp, respBody)
+	}
+
+	e.Error = t.scrubber.scrub(e.Error)
+
+	if writeErr := audit.Write(t.path, e); writeErr != nil {
+		log.Warnf("unable to write to the audit log: %s", writeErr)
+	}
+
+	return resp, err
+}
+
+// entry returns a new audit log entry for requests which change data, or
+// nil for reads.
+func (t *auditTransport) entry(req *http.Request, body []byte) *audit.Entry {
+	var gql graphQLRequest
+	if req.Method == http.MethodPost && json.Unmarshal(body, &gql) == nil && gql.Query != "" {
+		if !isMutation(gql.Query) {
+			return nil
+		}
+
+		e := t.newEntry()
+		e.Operation = "mutation"
+		if m := mutationFieldRegexp.FindStringSubmatch(strings.TrimSpace(graphQLCommentRegexp.ReplaceAllString(gql.Query, ""))); m != nil {
+			e.Operation = m[1]
+		}
+
+		if id, ok := gql.Variables["accountId"]; ok {
+			if accountID, err := strconv.Atoi(fmt.Sprint(id)); err == nil {
+				e.AccountID = accountID
+			}
+		}
+
+		e.Targets = auditTargets(gql.Variables)
+
+		return e
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+
+	e := t.newEntry()
+	e.Operation = fmt.Sprintf("%s %s", req.Method, req.URL.Path)
+
+	// REST resources are identified by the IDs in their path
+	for _, segment := range strings.Split(req.URL.Path, "/") {
+		if _, err := strconv.Atoi(strings.TrimSuffix(segment, ".json")); err == nil {
+			e.Targets = append(e.Targets, strings.TrimSuffix(segment, ".json"))
+		}
+	}
+
+	return e
+}
+
+func (t *auditTransport) newEntry() *audit.Entry {
+	e := audit.NewEntry()
+	e.Profile = t.profile
+	e.AccountID = t.accountID
+
+	for i, arg := range e.Args {
+		e.Args[i] = t.scrubber.scrub(arg)
+	}
+
+	return e
+}
+
+// auditTargets returns the values of the variables which look like GUIDs
+// or IDs, other than the account ID, in a stable order.
+func auditTargets(variables map[string]interface{}) []string {
+	var targets []string
+
+	var walk func(name string, value interface{})
+	walk = func(name string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for k, child := range v {
+				walk(k, child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(name, child)
+			}
+		case nil:
+		default:
+			if strings.EqualFold(name, "accountId") || !targetVariableRegexp.MatchString(name) {
+				return
+			}
+
+			targets = append(targets, fmt.Sprint(v))
+		}
+	}
+
+	for k, v := range variables {
+		walk(k, v)
+	}
+
+	sort.Strings(targets)
+
+	return targets
+}
+
+// auditResult returns the result of a request, which fails with an error
+// status or with GraphQL errors, whether in the response or the payload of
+// the mutation.
+func auditResult(resp *http.Response, body []byte) (string, string) {
+	if resp.StatusCode >= http.StatusBadRequest {
+		return audit.ResultError, resp.Status
+	}
+
+	var data interface{}
+	if json.Unmarshal(body, &data) != nil {
+		return audit.ResultSuccess, ""
+	}
+
+	if messages := graphQLErrors(data); len(messages) > 0 {
+		return audit.ResultError, strings.Join(messages, "; ")
+	}
+
+	return audit.ResultSuccess, ""
+}
+
+// graphQLErrors returns the messages of any non-empty errors lists
+func graphQLErrors(data interface{}) []string {
+	var messages []string
+
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			errs, ok := child.([]interface{})
+			if k != "errors" || !ok {
+				messages = append(messages, graphQLErrors(child)...)
+				continue
+			}
+
+			for _, e := range errs {
+				if m, ok := e.(map[string]interface{}); ok && m["message"] != nil {
+					messages = append(messages, fmt.Sprint(m["message"]))
+				} else {
+					messages = append(messages, fmt.Sprint(e))
+				}
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			messages = append(messages, graphQLErrors(child)...)
+		}
+	}
+
+	return messages
+}
@@ -0,0 +1,134 @@
+// +build unit
+
+package client
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/audit"
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+)
+
+func TestAuditTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "taggingDeleteTagFromEntity") {
+			_, _ = w.Write([]byte(`{"data":{"taggingDeleteTagFromEntity":{"errors":[{"message":"tag not found"}]}}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{AuditLogFile: filepath.Join(dir, "audit.jsonl")}
+	p := &credentials.Profile{APIKey: "my-api-key", AccountID: 5}
+	c := http.Client{Transport: newAuditTransport(cfg, "prod", p, http.DefaultTransport.(*http.Transport))}
+
+	post := func(path string, body string) {
+		resp, postErr := c.Post(srv.URL+path, "application/json", strings.NewReader(body))
+		require.NoError(t, postErr)
+		resp.Body.Close()
+	}
+
+	// Reads are not logged
+	post("/graphql", `{"query":"{ actor { user { name } } }"}`)
+	resp, err := c.Get(srv.URL + "/v2/applications.json")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	post("/graphql", `{"query":"mutation($guid: EntityGuid!) { taggingAddTagsToEntity(guid: $guid) { errors { message } } }","variables":{"guid":"MTIz","tags":[{"key":"env"}]}}`)
+	post("/graphql", `{"query":"mutation { taggingDeleteTagFromEntity(guid: \"MTIz\") { errors { message } } }","variables":{}}`)
+	post("/graphql", `{"query":"mutation($accountId: Int!, $ids: [ID!]) { apiAccessDeleteKeys(accountId: $accountId, keys: {ids: $ids}) { errors } }","variables":{"accountId":6,"keys":{"ids":["2","1"]}}}`)
+	post("/v2/applications/123/deployments.json", `{"deployment":{"revision":"1"}}`)
+
+	entries, err := audit.Read(cfg.AuditLogFile, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, "prod", entries[0].Profile)
+	assert.Equal(t, 5, entries[0].AccountID)
+	assert.Equal(t, "taggingAddTagsToEntity", entries[0].Operation)
+	assert.Equal(t, []string{"MTIz"}, entries[0].Targets)
+	assert.Equal(t, audit.ResultSuccess, entries[0].Result)
+
+	assert.Equal(t, "taggingDeleteTagFromEntity", entries[1].Operation)
+	assert.Equal(t, audit.ResultError, entries[1].Result)
+	assert.Equal(t, "tag not found", entries[1].Error)
+
+	assert.Equal(t, 6, entries[2].AccountID)
+	assert.Equal(t, []string{"1", "2"}, entries[2].Targets)
+
+	assert.Equal(t, "POST /v2/applications/123/deployments.json", entries[3].Operation)
+	assert.Equal(t, []string{"123"}, entries[3].Targets)
+}
+
+func TestAuditRetriedRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	srv, calls := failingServer(t, http.StatusTooManyRequests, http.StatusTooManyRequests)
+	defer srv.Close()
+
+	os.Setenv("NEW_RELIC_NERDGRAPH_URL", srv.URL)
+	defer os.Unsetenv("NEW_RELIC_NERDGRAPH_URL")
+
+	cfg := &config.Config{
+		LogLevel:     "error",
+		AuditLogFile: filepath.Join(dir, "audit.jsonl"),
+		HTTP: config.HTTPConfig{
+			RetryMaxAttempts: 3,
+			RetryMinBackoff:  time.Millisecond,
+			RetryMaxBackoff:  time.Millisecond,
+		},
+	}
+
+	nrClient, err := newClient(cfg, "prod", &credentials.Profile{APIKey: "apiKey"}, "US")
+	require.NoError(t, err)
+
+	_, err = nrClient.NerdGraph.Query(`mutation { taggingAddTagsToEntity(guid: "MTIz") { errors { message } } }`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+
+	// Each logical request is logged once
+	entries, err := audit.Read(cfg.AuditLogFile, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, audit.ResultSuccess, entries[0].Result)
+}
+
+func TestAuditDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := &config.Config{AuditLogFile: filepath.Join(dir, "audit.jsonl")}
+	dryRunTransport := wrapTransport(&dryRunTransport{scrubber: &scrubber{}, next: http.DefaultTransport})
+	c := http.Client{Transport: newAuditTransport(cfg, "prod", nil, dryRunTransport)}
+	defer func() { dryRunRequests = nil }()
+
+	// Requests stopped by --dry-run changed nothing, so they are not logged
+	_, err = c.Post("http://localhost/graphql", "application/json", strings.NewReader(`{"query":"mutation { taggingAddTagsToEntity(guid: \"MTIz\") { errors { message } } }"}`))
+	assert.True(t, errors.Is(err, ErrDryRun))
+
+	_, err = os.Stat(cfg.AuditLogFile)
+	assert.True(t, os.IsNotExist(err))
+}
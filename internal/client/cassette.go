@@ -0,0 +1,251 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// RecordEnvVar names the cassette file to record HTTP interactions to
+	RecordEnvVar = "NEW_RELIC_CLI_RECORD"
+
+	// ReplayEnvVar names the cassette file to replay HTTP interactions from,
+	// instead of sending requests over the network
+	ReplayEnvVar = "NEW_RELIC_CLI_REPLAY"
+
+	cassetteVersion = 1
+)
+
+var (
+	cassetteOnce   sync.Once
+	activeCassette *cassette
+	cassetteErr    error
+)
+
+// cassette holds the HTTP interactions recorded from, or replayed to, a CLI
+// invocation.  Credentials are scrubbed before anything is written.
+type cassette struct {
+	Version      int            `json:"version"`
+	Interactions []*interaction `json:"interactions"`
+
+	path      string
+	replaying bool
+	scrubber  *scrubber
+	mu        sync.Mutex
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+
+	used bool
+}
+
+type recordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	recordedBody
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	recordedBody
+}
+
+// recordedBody keeps text bodies readable, and others base64 encoded
+type recordedBody struct {
+	Body       string `json:"body,omitempty"`
+	BodyBase64 []byte `json:"bodyBase64,omitempty"`
+}
+
+func (b *recordedBody) set(body []byte) {
+	if utf8.Valid(body) {
+		b.Body = string(body)
+	} else {
+		b.BodyBase64 = body
+	}
+}
+
+func (b *recordedBody) bytes() []byte {
+	if b.BodyBase64 != nil {
+		return b.BodyBase64
+	}
+
+	return []byte(b.Body)
+}
+
+// loadCassette returns the cassette named by the record or replay
+// environment variable, or nil when neither is set.
+func loadCassette() (*cassette, error) {
+	cassetteOnce.Do(func() {
+		if path := os.Getenv(ReplayEnvVar); path != "" {
+			activeCassette, cassetteErr = readCassette(path)
+		} else if path := os.Getenv(RecordEnvVar); path != "" {
+			log.Debugf("recording HTTP interactions to %s", path)
+			activeCassette = &cassette{Version: cassetteVersion, path: path, scrubber: &scrubber{}}
+		}
+	})
+
+	return activeCassette, cassetteErr
+}
+
+func readCassette(path string) (*cassette, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cassette: %s", err)
+	}
+
+	c := &cassette{path: path, replaying: true, scrubber: &scrubber{}}
+	if err = json.Unmarshal(content, c); err != nil {
+		return nil, fmt.Errorf("error parsing cassette %s: %s", path, err)
+	}
+
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("unsupported cassette version %d in %s", c.Version, path)
+	}
+
+	log.Debugf("replaying %d HTTP interactions from %s", len(c.Interactions), path)
+
+	return c, nil
+}
+
+// cassetteTransport records the interactions of the requests sent through
+// it, or replays them without using the network.
+type cassetteTransport struct {
+	cassette *cassette
+	next     http.RoundTripper
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close() // nolint:errcheck
+	}
+
+	c := t.cassette
+	if c.replaying {
+		return c.replay(req, body), nil
+	}
+
+	r := req.Clone(req.Context())
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close() // nolint:errcheck
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	if err = c.record(req, body, resp, respBody); err != nil {
+		log.Warnf("unable to record HTTP interaction: %s", err)
+	}
+
+	return resp, nil
+}
+
+// record adds an interaction and writes the cassette, so it is complete
+// even if the CLI exits part way through.
+func (c *cassette) record(req *http.Request, body []byte, resp *http.Response, respBody []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := &interaction{
+		Request: recordedRequest{
+			Method: req.Method,
+			URL:    c.scrubber.scrub(req.URL.String()),
+			Header: c.scrubber.scrubHeader(req.Header),
+		},
+		Response: recordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     c.scrubber.scrubHeader(resp.Header),
+		},
+	}
+	i.Request.set(c.scrubber.scrubBody(body))
+	i.Response.set(c.scrubber.scrubBody(respBody))
+
+	c.Interactions = append(c.Interactions, i)
+
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.path, content, 0600)
+}
+
+// replay returns the response of the first unused interaction matching the
+// request.  Requests which were not recorded get a 400 response, so they
+// fail without being retried.
+func (c *cassette) replay(req *http.Request, body []byte) *http.Response {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	method := req.Method
+	url := c.scrubber.scrub(req.URL.String())
+	scrubbed := c.scrubber.scrubBody(body)
+
+	for _, i := range c.Interactions {
+		if i.used || i.Request.Method != method || i.Request.URL != url || !bytes.Equal(i.Request.bytes(), scrubbed) {
+			continue
+		}
+
+		i.used = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Response.Header.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(i.Response.bytes())),
+			ContentLength: int64(len(i.Response.bytes())),
+			Request:       req,
+		}
+	}
+
+	msg := fmt.Sprintf("no recorded interaction for %s %s in cassette %s", method, url, c.path)
+	log.Debug(msg)
+
+	errBody, _ := json.Marshal(map[string]interface{}{
+		"errors": []map[string]string{{"message": msg}},
+	})
+
+	return &http.Response{
+		Status:        "400 Bad Request",
+		StatusCode:    http.StatusBadRequest,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(errBody)),
+		ContentLength: int64(len(errBody)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,120 @@
+// +build unit
+
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassetteRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-cassette")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cassettes", "test.json")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/binary" {
+			_, _ = w.Write([]byte{0xff, 0xfe, 0x00})
+			return
+		}
+
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = w.Write([]byte(`{"data":{"licenseKey":"0123456789abcdef","userKey":"NRAK-ABCDEFGHIJKLMNOP"}}`))
+	}))
+	defer srv.Close()
+
+	// Record
+	recorder := &cassette{Version: cassetteVersion, path: path, scrubber: &scrubber{}}
+	recorder.scrubber.addSecrets("my-api-key")
+
+	c := http.Client{Transport: wrapTransport(&cassetteTransport{cassette: recorder, next: http.DefaultTransport})}
+
+	req, err := http.NewRequest("POST", srv.URL+"/graphql", strings.NewReader(`{"query":"key my-api-key"}`))
+	require.NoError(t, err)
+	req.Header.Set("Api-Key", "my-api-key")
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The live response is not scrubbed
+	assert.Contains(t, string(body), "0123456789abcdef")
+
+	resp, err = c.Get(srv.URL + "/binary")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	for _, secret := range []string{"my-api-key", "0123456789abcdef", "NRAK-ABCDEFGHIJKLMNOP", "session=secret"} {
+		assert.NotContains(t, string(content), secret)
+	}
+
+	// Replay, with different keys and no server
+	srv.Close()
+
+	player, err := readCassette(path)
+	require.NoError(t, err)
+	player.scrubber.addSecrets("other-api-key")
+
+	c = http.Client{Transport: wrapTransport(&cassetteTransport{cassette: player})}
+
+	req, err = http.NewRequest("POST", srv.URL+"/graphql", strings.NewReader(`{"query":"key other-api-key"}`))
+	require.NoError(t, err)
+
+	resp, err = c.Do(req)
+	require.NoError(t, err)
+	body, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"data":{"licenseKey":"REDACTED","userKey":"REDACTED"}}`, string(body))
+
+	resp, err = c.Get(srv.URL + "/binary")
+	require.NoError(t, err)
+	body, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []byte{0xff, 0xfe, 0x00}, body)
+
+	// Each interaction is only replayed once
+	resp, err = c.Get(srv.URL + "/binary")
+	require.NoError(t, err)
+	body, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(body), "no recorded interaction for GET")
+}
+
+func TestReadCassetteInvalid(t *testing.T) {
+	_, err := readCassette("does-not-exist.json")
+	assert.Error(t, err)
+
+	f, err := ioutil.TempFile("", "newrelic-cli-cassette")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(`{"version":99}`)
+	require.NoError(t, err)
+	f.Close()
+
+	_, err = readCassette(f.Name())
+	assert.Error(t, err)
+}
@@ -19,18 +19,19 @@ var (
 // CreateNRClient initializes the New Relic client.
 func CreateNRClient(cfg *config.Config, creds *credentials.Credentials) (*newrelic.NewRelic, *credentials.Profile, error) {
 	var (
-		err               error
-		apiKey            string
-		insightsInsertKey string
-		regionValue       string
+		err         error
+		apiKey      string
+		regionValue string
 	)
 
 	// Create the New Relic Client
-	defProfile := creds.Default()
+	defProfile, err := creds.ActiveProfile()
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if defProfile != nil {
 		apiKey = defProfile.APIKey
-		insightsInsertKey = defProfile.InsightsInsertKey
 		regionValue = defProfile.Region
 	}
 
@@ -38,15 +39,40 @@ func CreateNRClient(cfg *config.Config, creds *credentials.Credentials) (*newrel
 		return nil, nil, errors.New("an API key is required, set a default profile or use the NEW_RELIC_API_KEY environment variable")
 	}
 
+	nrClient, err := newClient(cfg, creds.ActiveProfileName(), defProfile, regionValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nrClient, defProfile, nil
+}
+
+// CreateNRClientForProfile initializes a New Relic client using the keys of
+// the given profile, in the given region.
+func CreateNRClientForProfile(cfg *config.Config, p *credentials.Profile, regionValue string) (*newrelic.NewRelic, error) {
+	if p.APIKey == "" {
+		return nil, errors.New("an API key is required")
+	}
+
+	return newClient(cfg, "", p, regionValue)
+}
+
+func newClient(cfg *config.Config, profileName string, p *credentials.Profile, regionValue string) (*newrelic.NewRelic, error) {
 	userAgent := fmt.Sprintf("newrelic-cli/%s (https://github.com/newrelic/newrelic-cli)", version)
 
+	transport, err := NewTransport(cfg, p)
+	if err != nil {
+		return nil, err
+	}
+
 	cfgOpts := []newrelic.ConfigOption{
-		newrelic.ConfigPersonalAPIKey(apiKey),
-		newrelic.ConfigInsightsInsertKey(insightsInsertKey),
+		newrelic.ConfigPersonalAPIKey(p.APIKey),
+		newrelic.ConfigInsightsInsertKey(p.InsightsInsertKey),
 		newrelic.ConfigLogLevel(cfg.LogLevel),
 		newrelic.ConfigRegion(regionValue),
 		newrelic.ConfigUserAgent(userAgent),
 		newrelic.ConfigServiceName(serviceName),
+		newrelic.ConfigHTTPTransport(newAuditTransport(cfg, profileName, p, newRetryTransport(retryPolicy(cfg), transport))),
 	}
 
 	nerdGraphURLOverride := os.Getenv("NEW_RELIC_NERDGRAPH_URL")
@@ -57,8 +83,8 @@ func CreateNRClient(cfg *config.Config, creds *credentials.Credentials) (*newrel
 	nrClient, err := newrelic.New(cfgOpts...)
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create New Relic client with error: %s", err)
+		return nil, fmt.Errorf("unable to create New Relic client with error: %s", err)
 	}
 
-	return nrClient, defProfile, nil
+	return nrClient, nil
 }
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+var (
+	dryRun bool
+
+	// The requests which were not sent, to be shown by ReportDryRun
+	dryRunMutex    sync.Mutex
+	dryRunRequests []*DryRunRequest
+
+	graphQLCommentRegexp = regexp.MustCompile(`(?m)^\s*#.*$`)
+
+	// ErrDryRun is returned for requests which were not sent because of --dry-run
+	ErrDryRun = errors.New("request not sent, --dry-run is set")
+)
+
+// SetDryRun stops requests which would change data in New Relic from being
+// sent.  They fail with ErrDryRun, and are shown by ReportDryRun instead.
+func SetDryRun(enabled bool) {
+	dryRun = enabled
+
+	if enabled {
+		log.AddHook(dryRunHook{})
+	}
+}
+
+// DryRun returns true when requests which would change data are not sent
+func DryRun() bool {
+	return dryRun
+}
+
+// DryRunRequest describes a request which was not sent because of --dry-run
+type DryRunRequest struct {
+	Method    string                 `json:"method" yaml:"method"`
+	URL       string                 `json:"url" yaml:"url"`
+	Operation string                 `json:"operation,omitempty" yaml:"operation,omitempty"`
+	Variables map[string]interface{} `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Body      string                 `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// dryRunTransport passes queries through, and stops at the first GraphQL
+// mutation or REST request which isn't a read.
+type dryRunTransport struct {
+	scrubber *scrubber
+	next     http.RoundTripper
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close() // nolint:errcheck
+	}
+
+	r := &DryRunRequest{
+		Method: req.Method,
+		URL:    t.scrubber.scrub(req.URL.String()),
+	}
+
+	var gql graphQLRequest
+	if req.Method == http.MethodPost && json.Unmarshal(body, &gql) == nil && gql.Query != "" {
+		if !isMutation(gql.Query) {
+			return t.send(req, body)
+		}
+
+		r.Operation = strings.Join(strings.Fields(gql.Query), " ")
+		r.Variables = gql.Variables
+
+		// Scrub the variables in their JSON form
+		if v, err := json.Marshal(gql.Variables); err == nil {
+			_ = json.Unmarshal(t.scrubber.scrubBody(v), &r.Variables)
+		}
+	} else {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return t.send(req, body)
+		}
+
+		r.Body = string(t.scrubber.scrubBody(body))
+	}
+
+	log.Debugf("dry run, not sending %s %s", r.Method, r.URL)
+
+	dryRunMutex.Lock()
+	dryRunRequests = append(dryRunRequests, r)
+	dryRunMutex.Unlock()
+
+	return nil, ErrDryRun
+}
+
+// ReportDryRun prints the requests which were not sent because of --dry-run.
+// A single request is printed on its own, several as a list.
+func ReportDryRun() error {
+	dryRunMutex.Lock()
+	requests := dryRunRequests
+	dryRunRequests = nil
+	dryRunMutex.Unlock()
+
+	switch len(requests) {
+	case 0:
+		return nil
+	case 1:
+		return output.Print(requests[0])
+	}
+
+	return output.Print(requests)
+}
+
+// dryRunHook reports the dry run when a command gives up with log.Fatal
+// because of the ErrDryRun returned in place of a response.  The CLI then
+// exits successfully, since nothing has gone wrong.  Any other fatal error
+// is left to end the command as usual.
+type dryRunHook struct{}
+
+func (dryRunHook) Levels() []log.Level {
+	return []log.Level{log.FatalLevel}
+}
+
+func (dryRunHook) Fire(entry *log.Entry) error {
+	if !isDryRunEntry(entry) {
+		return nil
+	}
+
+	log.Debugf("dry run ended the command: %s", entry.Message)
+
+	utils.LogIfError(ReportDryRun())
+	utils.LogIfError(output.Flush())
+	os.Exit(0)
+
+	return nil
+}
+
+// isDryRunEntry returns true when a log entry is for an error wrapping
+// ErrDryRun.  log.Fatal(err) keeps only the text of the error, which
+// includes the text of ErrDryRun when it is wrapped.
+func isDryRunEntry(entry *log.Entry) bool {
+	if err, ok := entry.Data[log.ErrorKey].(error); ok {
+		return errors.Is(err, ErrDryRun)
+	}
+
+	return strings.Contains(entry.Message, ErrDryRun.Error())
+}
+
+func (t *dryRunTransport) send(req *http.Request, body []byte) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	if body != nil {
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return t.next.RoundTrip(r)
+}
+
+// isMutation returns true for GraphQL operations which change data
+func isMutation(query string) bool {
+	query = strings.TrimSpace(graphQLCommentRegexp.ReplaceAllString(query, ""))
+
+	return strings.HasPrefix(query, "mutation")
+}
@@ -0,0 +1,150 @@
+// +build unit
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/output"
+)
+
+func TestDryRunTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-dryrun")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var sent []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method)
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	s := &scrubber{}
+	s.addSecrets("my-api-key")
+	c := http.Client{Transport: wrapTransport(&dryRunTransport{scrubber: s, next: http.DefaultTransport})}
+
+	// Reads are sent
+	resp, err := c.Post(srv.URL+"/graphql", "application/json", strings.NewReader(`{"query":"# comment\n query { actor { user { name } } }"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	resp, err = c.Get(srv.URL + "/v2/applications.json")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"POST", "GET"}, sent)
+
+	// Mutations are shown, with secrets redacted
+	path := filepath.Join(dir, "mutation.json")
+	require.NoError(t, output.SetOutputFile(path, false))
+
+	body := `{"query":"mutation ($key: String!) {\n  tag(key: $key) {\n    errors\n  }\n}","variables":{"key":"my-api-key","guid":"MTIz"}}`
+	_, err = c.Post(srv.URL+"/graphql", "application/json", strings.NewReader(body))
+	assert.True(t, errors.Is(err, ErrDryRun))
+	assert.Len(t, sent, 2)
+
+	require.NoError(t, ReportDryRun())
+	require.NoError(t, output.Flush())
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var shown DryRunRequest
+	require.NoError(t, json.Unmarshal(content, &shown))
+	assert.Equal(t, "POST", shown.Method)
+	assert.Equal(t, "mutation ($key: String!) { tag(key: $key) { errors } }", shown.Operation)
+	assert.Equal(t, map[string]interface{}{"key": "REDACTED", "guid": "MTIz"}, shown.Variables)
+
+	// Other requests which change data are shown with their body
+	path = filepath.Join(dir, "rest.json")
+	require.NoError(t, output.SetOutputFile(path, false))
+
+	_, err = c.Post(srv.URL+"/v2/applications/1/deployments.json", "application/json", strings.NewReader(`{"revision":"1"}`))
+	assert.True(t, errors.Is(err, ErrDryRun))
+	assert.Len(t, sent, 2)
+
+	require.NoError(t, ReportDryRun())
+	require.NoError(t, output.Flush())
+
+	content, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(content, &shown))
+	assert.Equal(t, `{"revision":"1"}`, shown.Body)
+
+	// Every request which was not sent is shown
+	path = filepath.Join(dir, "several.json")
+	require.NoError(t, output.SetOutputFile(path, false))
+
+	for i := 0; i < 2; i++ {
+		_, err = c.Post(srv.URL+"/graphql", "application/json", strings.NewReader(body))
+		assert.True(t, errors.Is(err, ErrDryRun))
+	}
+
+	require.NoError(t, ReportDryRun())
+	require.NoError(t, output.Flush())
+
+	content, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var several []DryRunRequest
+	require.NoError(t, json.Unmarshal(content, &several))
+	assert.Len(t, several, 2)
+}
+
+func TestIsMutation(t *testing.T) {
+	assert.True(t, isMutation("mutation { tag }"))
+	assert.True(t, isMutation("# a comment\n  mutation Tag { tag }"))
+	assert.False(t, isMutation("query { actor }"))
+	assert.False(t, isMutation("{ actor { mutation } }"))
+}
+
+func TestDryRunHookExit(t *testing.T) {
+	// The fatal error is raised in a copy of the test binary, which exits
+	if fatal := os.Getenv("DRY_RUN_FATAL"); fatal != "" {
+		SetDryRun(true)
+		dryRunRequests = []*DryRunRequest{{Method: "POST", URL: "http://localhost/graphql"}}
+
+		if fatal == "dry-run" {
+			log.Fatal(fmt.Errorf("POST http://localhost/graphql giving up after 1 attempt(s): %w", ErrDryRun))
+		}
+
+		log.Fatal("invalid value for --name")
+	}
+
+	run := func(fatal string) error {
+		cmd := exec.Command(os.Args[0], "-test.run=TestDryRunHookExit")
+		cmd.Env = append(os.Environ(), "DRY_RUN_FATAL="+fatal)
+		return cmd.Run()
+	}
+
+	// A request stopped by --dry-run ends the command successfully
+	assert.NoError(t, run("dry-run"))
+
+	// Any other fatal error still fails, even after a request was stopped
+	err := run("other")
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 1, exitErr.ExitCode())
+}
+
+func TestIsDryRunEntry(t *testing.T) {
+	assert.True(t, isDryRunEntry(&log.Entry{Message: "Post http://localhost: " + ErrDryRun.Error()}))
+	assert.True(t, isDryRunEntry(log.WithError(fmt.Errorf("wrapped: %w", ErrDryRun))))
+	assert.False(t, isDryRunEntry(&log.Entry{Message: "invalid value for --name"}))
+	assert.False(t, isDryRunEntry(log.WithError(errors.New("500 Internal Server Error"))))
+}
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"net/http"
+
 	log "github.com/sirupsen/logrus"
 
 	"github.com/newrelic/newrelic-cli/internal/config"
@@ -33,7 +35,7 @@ func WithClientAndProfile(f func(c *newrelic.NewRelic, p *credentials.Profile))
 	WithClientAndProfileFrom(config.DefaultConfigDirectory, f)
 }
 
-// WithClientAndProfileFrom returns a New Relic client and default profile used to initialize it,
+// WithClientAndProfileFrom returns a New Relic client and the profile used to initialize it,
 // after environment oveerrides have been applied.
 func WithClientAndProfileFrom(configDir string, f func(c *newrelic.NewRelic, p *credentials.Profile)) {
 	config.WithConfigFrom(configDir, func(cfg *config.Config) {
@@ -47,3 +49,18 @@ func WithClientAndProfileFrom(configDir string, f func(c *newrelic.NewRelic, p *
 		})
 	})
 }
+
+// WithHTTPClient returns an HTTP client for requests made outside of the New
+// Relic client, using the proxy and TLS settings of the profile in use.
+func WithHTTPClient(f func(c *http.Client)) {
+	config.WithConfig(func(cfg *config.Config) {
+		credentials.WithCredentials(func(creds *credentials.Credentials) {
+			httpClient, err := NewHTTPClient(cfg, creds.Default())
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			f(httpClient)
+		})
+	})
+}
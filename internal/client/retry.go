@@ -0,0 +1,215 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+)
+
+// RetryPolicy controls how requests which are rate limited, or fail with a
+// server or connection error, are retried.
+type RetryPolicy struct {
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+var retryOverrides RetryPolicy
+
+// SetRetryOverrides overrides the configured retry policy with the values
+// given on the command line.  Zero values keep the configured value.
+func SetRetryOverrides(maxAttempts int, maxBackoff time.Duration) {
+	retryOverrides = RetryPolicy{
+		MaxAttempts: maxAttempts,
+		MaxBackoff:  maxBackoff,
+	}
+}
+
+// retryPolicy returns the retry policy from the config, with any overrides
+// applied.
+func retryPolicy(cfg *config.Config) RetryPolicy {
+	p := RetryPolicy{
+		MaxAttempts: cfg.HTTP.RetryMaxAttempts,
+		MinBackoff:  cfg.HTTP.RetryMinBackoff,
+		MaxBackoff:  cfg.HTTP.RetryMaxBackoff,
+	}
+
+	if retryOverrides.MaxAttempts > 0 {
+		p.MaxAttempts = retryOverrides.MaxAttempts
+	}
+
+	if retryOverrides.MaxBackoff > 0 {
+		p.MaxBackoff = retryOverrides.MaxBackoff
+	}
+
+	return p
+}
+
+// retryTransport retries requests according to a retry policy.  It sits
+// beneath the New Relic client library, which makes up to three retries of
+// its own once the policy gives up, so a request which keeps failing may be
+// sent up to four times the policy's attempts.  Failures which the policy
+// recovers from are never seen by the library.
+type retryTransport struct {
+	policy RetryPolicy
+	next   http.RoundTripper
+}
+
+// newRetryTransport returns a transport applying the retry policy to the
+// requests sent through base.
+func newRetryTransport(policy RetryPolicy, base *http.Transport) *http.Transport {
+	if policy.MaxAttempts <= 1 {
+		return base
+	}
+
+	return wrapTransport(&retryTransport{policy: policy, next: base})
+}
+
+// wrapTransport returns a transport which sends every request through rt.
+// The New Relic client library only accepts an *http.Transport, so rt is
+// registered as the handler for both schemes.
+func wrapTransport(rt http.RoundTripper) *http.Transport {
+	t := &http.Transport{}
+	t.RegisterProtocol("http", rt)
+	t.RegisterProtocol("https", rt)
+
+	return t
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Keep the body so it can be sent again
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close() // nolint:errcheck
+	}
+
+	idempotent := isIdempotent(req, body)
+
+	for attempt := 1; ; attempt++ {
+		r := req.Clone(req.Context())
+		if body != nil {
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		resp, err := t.next.RoundTrip(r)
+
+		if attempt >= t.policy.MaxAttempts || !shouldRetry(idempotent, resp, err) {
+			return resp, err
+		}
+
+		wait := t.policy.backoff(attempt, resp)
+
+		reason := fmt.Sprint(err)
+		if resp != nil {
+			reason = resp.Status
+
+			// Drain the body so the connection can be reused
+			io.Copy(ioutil.Discard, resp.Body) // nolint:errcheck
+			resp.Body.Close()                  // nolint:errcheck
+		}
+
+		log.Debugf("retrying %s %s in %s after attempt %d of %d: %s", req.Method, req.URL, wait, attempt, t.policy.MaxAttempts, reason)
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// shouldRetry returns true for rate limits and failed connections.  Server
+// errors other than 501 Not Implemented, and dropped connections, are only
+// retried for idempotent requests, since a change may have been made before
+// the request failed.
+func shouldRetry(idempotent bool, resp *http.Response, err error) bool {
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return true
+		}
+
+		return idempotent && (errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF))
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return idempotent && resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented
+}
+
+// isIdempotent returns true for reads, which can be sent again safely:
+// GraphQL queries and REST requests which don't change data.
+func isIdempotent(req *http.Request, body []byte) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		var gql graphQLRequest
+		return json.Unmarshal(body, &gql) == nil && gql.Query != "" && !isMutation(gql.Query)
+	}
+
+	return false
+}
+
+// backoff returns the wait before the next attempt.  A Retry-After header is
+// honored, otherwise the wait doubles with each attempt up to the maximum,
+// with jitter so concurrent clients don't retry in step.
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait
+		}
+	}
+
+	wait := float64(p.MinBackoff) * math.Pow(2, float64(attempt-1))
+	if wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+
+	// Wait between half and all of the backoff
+	half := time.Duration(wait / 2)
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as a date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+
+		return wait, true
+	}
+
+	return 0, false
+}
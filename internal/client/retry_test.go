@@ -0,0 +1,233 @@
+// +build unit
+
+package client
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+)
+
+var testPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+// failingServer responds with each status in turn, then with 200 OK.  Every
+// attempt must send the same body.
+func failingServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	var (
+		calls int32
+		first string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.NotEmpty(t, body)
+
+		if n == 1 {
+			first = string(body)
+		}
+		assert.Equal(t, first, string(body))
+
+		if n <= len(statuses) {
+			switch statuses[n-1] {
+			case 0:
+				// Drop the connection without responding
+				conn, _, err := w.(http.Hijacker).Hijack()
+				require.NoError(t, err)
+				conn.Close()
+			case http.StatusTooManyRequests:
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			default:
+				w.WriteHeader(statuses[n-1])
+			}
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"actor":{"user":{"name":"test"}}}}`))
+	}))
+
+	return srv, &calls
+}
+
+func post(t *testing.T, policy RetryPolicy, url string) *http.Response {
+	c := http.Client{Transport: newRetryTransport(policy, http.DefaultTransport.(*http.Transport).Clone())}
+
+	resp, err := c.Post(url, "application/json", strings.NewReader(`{"query":"test"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	return resp
+}
+
+func TestRetryTransport(t *testing.T) {
+	srv, calls := failingServer(t, http.StatusTooManyRequests, http.StatusServiceUnavailable, 0)
+	defer srv.Close()
+
+	resp := post(t, testPolicy, srv.URL)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+}
+
+func TestRetryTransportGivesUp(t *testing.T) {
+	srv, calls := failingServer(t, 502, 502, 502, 502, 502)
+	defer srv.Close()
+
+	resp := post(t, testPolicy, srv.URL)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+}
+
+func TestRetryTransportNotRetried(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusNotImplemented} {
+		srv, calls := failingServer(t, status)
+
+		resp := post(t, testPolicy, srv.URL)
+		assert.Equal(t, status, resp.StatusCode)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+		srv.Close()
+	}
+
+	// Retries can be disabled
+	srv, calls := failingServer(t, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	resp := post(t, RetryPolicy{MaxAttempts: 1}, srv.URL)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	for attempt, max := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		wait := p.backoff(attempt, nil)
+		assert.True(t, wait >= max/2 && wait <= max, "attempt %d waited %s", attempt, wait)
+	}
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "120")
+	assert.Equal(t, 2*time.Minute, p.backoff(1, resp))
+
+	resp.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.Equal(t, time.Duration(0), p.backoff(1, resp))
+
+	resp.Header.Set("Retry-After", "soon")
+	assert.True(t, p.backoff(1, resp) <= time.Second)
+}
+
+func TestRetryNerdGraph(t *testing.T) {
+	srv, calls := failingServer(t, http.StatusTooManyRequests, http.StatusTooManyRequests)
+	defer srv.Close()
+
+	os.Setenv("NEW_RELIC_NERDGRAPH_URL", srv.URL)
+	defer os.Unsetenv("NEW_RELIC_NERDGRAPH_URL")
+
+	cfg := &config.Config{
+		LogLevel: "error",
+		HTTP: config.HTTPConfig{
+			RetryMaxAttempts: 3,
+			RetryMinBackoff:  time.Millisecond,
+			RetryMaxBackoff:  time.Millisecond,
+		},
+	}
+
+	nrClient, err := newClient(cfg, "", &credentials.Profile{APIKey: "apiKey"}, "US")
+	require.NoError(t, err)
+
+	_, err = nrClient.NerdGraph.Query("{ actor { user { name } } }", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestRetryTransportMutation(t *testing.T) {
+	mutation := `{"query":"mutation { taggingAddTagsToEntity(guid: \"MTIz\") { errors { message } } }"}`
+
+	send := func(statuses ...int) (int, int32) {
+		srv, calls := failingServer(t, statuses...)
+		defer srv.Close()
+
+		c := http.Client{Transport: newRetryTransport(testPolicy, http.DefaultTransport.(*http.Transport).Clone())}
+
+		resp, err := c.Post(srv.URL, "application/json", strings.NewReader(mutation))
+		if err != nil {
+			return 0, atomic.LoadInt32(calls)
+		}
+		defer resp.Body.Close()
+
+		return resp.StatusCode, atomic.LoadInt32(calls)
+	}
+
+	// Rate limited mutations were not processed, so are retried
+	status, calls := send(http.StatusTooManyRequests, http.StatusTooManyRequests)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int32(3), calls)
+
+	// but one which failed may have made its change already
+	status, calls = send(http.StatusBadGateway)
+	assert.Equal(t, http.StatusBadGateway, status)
+	assert.Equal(t, int32(1), calls)
+
+	_, calls = send(0)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestShouldRetry(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	assert.True(t, shouldRetry(false, nil, dialErr))
+	assert.True(t, shouldRetry(true, nil, dialErr))
+
+	assert.True(t, shouldRetry(true, nil, io.EOF))
+	assert.False(t, shouldRetry(false, nil, io.EOF))
+	assert.False(t, shouldRetry(false, nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}))
+
+	assert.True(t, shouldRetry(false, &http.Response{StatusCode: http.StatusTooManyRequests}, nil))
+	assert.False(t, shouldRetry(false, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil))
+	assert.True(t, shouldRetry(true, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil))
+}
+
+func TestIsIdempotent(t *testing.T) {
+	get, _ := http.NewRequest(http.MethodGet, "http://localhost/v2/applications.json", nil)
+	assert.True(t, isIdempotent(get, nil))
+
+	post, _ := http.NewRequest(http.MethodPost, "http://localhost/graphql", nil)
+	assert.True(t, isIdempotent(post, []byte(`{"query":"{ actor { user { name } } }"}`)))
+	assert.False(t, isIdempotent(post, []byte(`{"query":"mutation { tag }"}`)))
+	assert.False(t, isIdempotent(post, []byte(`{"deployment":{"revision":"1"}}`)))
+
+	del, _ := http.NewRequest(http.MethodDelete, "http://localhost/v2/alerts_policies/1.json", nil)
+	assert.False(t, isIdempotent(del, nil))
+}
+
+func TestRetryOverrides(t *testing.T) {
+	defer SetRetryOverrides(0, 0)
+
+	cfg := &config.Config{HTTP: config.HTTPConfig{RetryMaxAttempts: 4, RetryMinBackoff: time.Second, RetryMaxBackoff: time.Minute}}
+
+	SetRetryOverrides(0, 0)
+	assert.Equal(t, RetryPolicy{MaxAttempts: 4, MinBackoff: time.Second, MaxBackoff: time.Minute}, retryPolicy(cfg))
+
+	SetRetryOverrides(1, 10*time.Second)
+	assert.Equal(t, RetryPolicy{MaxAttempts: 1, MinBackoff: time.Second, MaxBackoff: 10 * time.Second}, retryPolicy(cfg))
+}
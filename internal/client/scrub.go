@@ -0,0 +1,82 @@
+package client
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	redacted = "REDACTED"
+
+	// Shorter values are too likely to appear in other text, such as GUIDs
+	minSecretLength = 8
+)
+
+var (
+	// Headers which carry credentials
+	secretHeaders = []string{"Api-Key", "X-Api-Key", "X-Insert-Key", "X-License-Key", "X-Query-Key", "Authorization", "Cookie", "Set-Cookie"}
+
+	// JSON fields and key formats which hold credentials
+	secretFieldsRegexp = regexp.MustCompile(`("(?:apiKey|insightsInsertKey|insertKey|licenseKey)"\s*:\s*)"[^"]*"`)
+	secretKeysRegexp   = regexp.MustCompile(`NR(?:AK|II|IQ|AA)-[A-Za-z0-9_-]{8,}|\b[A-Za-z0-9]{36}NRAL\b`)
+)
+
+// scrubber replaces credentials with a placeholder, both the keys of the
+// profiles in use and anything which looks like a key.
+type scrubber struct {
+	secrets []string
+	mu      sync.Mutex
+}
+
+// addSecrets adds values to scrub wherever they appear
+func (s *scrubber) addSecrets(secrets ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			s.secrets = append(s.secrets, secret)
+		}
+	}
+}
+
+func (s *scrubber) scrub(text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, secret := range s.secrets {
+		text = strings.ReplaceAll(text, secret, redacted)
+	}
+
+	text = secretFieldsRegexp.ReplaceAllString(text, `$1"`+redacted+`"`)
+
+	return secretKeysRegexp.ReplaceAllString(text, redacted)
+}
+
+func (s *scrubber) scrubHeader(h http.Header) http.Header {
+	scrubbed := http.Header{}
+
+	for k, v := range h {
+		scrubbed[k] = v
+	}
+
+	for _, k := range secretHeaders {
+		if scrubbed.Get(k) != "" {
+			scrubbed.Set(k, redacted)
+		}
+	}
+
+	return scrubbed
+}
+
+// scrubBody scrubs text bodies, leaving binary ones alone
+func (s *scrubber) scrubBody(body []byte) []byte {
+	if !utf8.Valid(body) {
+		return body
+	}
+
+	return []byte(s.scrub(string(body)))
+}
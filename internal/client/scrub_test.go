@@ -0,0 +1,26 @@
+// +build unit
+
+package client
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrubber(t *testing.T) {
+	s := &scrubber{}
+	s.addSecrets("x", "my-api-key")
+
+	// Values too short to be keys are left alone
+	assert.Equal(t, `{"guid":"MTIzNDV8QVBNfEFQUExJQ0FUSU9OfDE"}`, s.scrub(`{"guid":"MTIzNDV8QVBNfEFQUExJQ0FUSU9OfDE"}`))
+
+	assert.Equal(t, "key REDACTED", s.scrub("key my-api-key"))
+	assert.Equal(t, `{"apiKey": "REDACTED","key":"env"}`, s.scrub(`{"apiKey": "abc","key":"env"}`))
+	assert.Equal(t, "REDACTED REDACTED", s.scrub("NRAK-ABCDEFGHIJKLMNOP 0123456789abcdef0123456789abcdef0123NRAL"))
+
+	h := s.scrubHeader(http.Header{"Api-Key": {"abc"}, "Accept": {"application/json"}})
+	assert.Equal(t, redacted, h.Get("Api-Key"))
+	assert.Equal(t, "application/json", h.Get("Accept"))
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+)
+
+// networkSettings are the proxy and TLS settings for requests, taken from
+// the profile in use or, when the profile doesn't set them, the config.
+type networkSettings struct {
+	proxy              string
+	caBundle           string
+	clientCert         string
+	clientKey          string
+	insecureSkipVerify bool
+}
+
+func networkSettingsFor(cfg *config.Config, p *credentials.Profile) networkSettings {
+	s := networkSettings{
+		proxy:              cfg.HTTP.Proxy,
+		caBundle:           cfg.HTTP.CABundle,
+		clientCert:         cfg.HTTP.ClientCert,
+		clientKey:          cfg.HTTP.ClientKey,
+		insecureSkipVerify: cfg.HTTP.InsecureSkipVerify,
+	}
+
+	if p == nil {
+		return s
+	}
+
+	if p.Proxy != "" {
+		s.proxy = p.Proxy
+	}
+
+	if p.CABundle != "" {
+		s.caBundle = p.CABundle
+	}
+
+	// The certificate and key belong together, so both come from the profile
+	if p.ClientCert != "" || p.ClientKey != "" {
+		s.clientCert = p.ClientCert
+		s.clientKey = p.ClientKey
+	}
+
+	return s
+}
+
+// NewTransport returns an HTTP transport using the proxy, CA bundle and
+// client certificate of the profile, falling back to those in the config.
+// Without a proxy setting the HTTPS_PROXY and NO_PROXY environment
+// variables are used.  Requests are recorded to, or replayed from, a
+// cassette when NEW_RELIC_CLI_RECORD or NEW_RELIC_CLI_REPLAY is set, and
+// mutations are shown rather than sent with --dry-run.
+func NewTransport(cfg *config.Config, p *credentials.Profile) (*http.Transport, error) {
+	s := networkSettingsFor(cfg, p)
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if s.proxy != "" {
+		proxyURL, err := url.Parse(s.proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q, expected a URL such as http://proxy.example.com:8080", s.proxy)
+		}
+
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q, use http, https or socks5", proxyURL.Scheme)
+		}
+
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	t.TLSClientConfig = tlsConfig
+
+	c, err := loadCassette()
+	if err != nil {
+		return nil, err
+	}
+
+	if c != nil {
+		if p != nil {
+			c.scrubber.addSecrets(p.APIKey, p.InsightsInsertKey, p.LicenseKey)
+		}
+
+		t = wrapTransport(&cassetteTransport{cassette: c, next: t})
+	}
+
+	if dryRun {
+		s := &scrubber{}
+		if p != nil {
+			s.addSecrets(p.APIKey, p.InsightsInsertKey, p.LicenseKey)
+		}
+
+		t = wrapTransport(&dryRunTransport{scrubber: s, next: t})
+	}
+
+	return t, nil
+}
+
+func (s networkSettings) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if s.caBundle != "" {
+		pem, err := ioutil.ReadFile(s.caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA bundle: %s", err)
+		}
+
+		// Trust the bundle as well as the system certificates
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			log.Debugf("unable to load the system certificates: %s", err)
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM encoded certificates found in CA bundle %s", s.caBundle)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if s.clientCert != "" || s.clientKey != "" {
+		if s.clientCert == "" || s.clientKey == "" {
+			return nil, errors.New("a client certificate requires both a certificate and a key file")
+		}
+
+		cert, err := tls.LoadX509KeyPair(s.clientCert, s.clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %s", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if s.insecureSkipVerify {
+		log.Warn("TLS certificate verification is disabled, connections are not secure")
+		tlsConfig.InsecureSkipVerify = true // nolint:gosec
+	}
+
+	return tlsConfig, nil
+}
+
+// NewHTTPClient returns an HTTP client for requests made outside of the New
+// Relic client, such as downloads, with the same proxy, TLS and retry
+// settings.
+func NewHTTPClient(cfg *config.Config, p *credentials.Profile) (*http.Client, error) {
+	t, err := NewTransport(cfg, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{Transport: newRetryTransport(retryPolicy(cfg), t)}, nil
+}
@@ -0,0 +1,144 @@
+// +build unit
+
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+)
+
+func TestNetworkSettingsFor(t *testing.T) {
+	cfg := &config.Config{HTTP: config.HTTPConfig{
+		Proxy:      "http://config-proxy:8080",
+		CABundle:   "config.pem",
+		ClientCert: "config-cert.pem",
+		ClientKey:  "config-key.pem",
+	}}
+
+	s := networkSettingsFor(cfg, nil)
+	assert.Equal(t, "http://config-proxy:8080", s.proxy)
+	assert.Equal(t, "config-cert.pem", s.clientCert)
+
+	s = networkSettingsFor(cfg, &credentials.Profile{Proxy: "http://profile-proxy:8080", ClientCert: "profile-cert.pem"})
+	assert.Equal(t, "http://profile-proxy:8080", s.proxy)
+	assert.Equal(t, "config.pem", s.caBundle)
+	assert.Equal(t, "profile-cert.pem", s.clientCert)
+	assert.Equal(t, "", s.clientKey)
+}
+
+func TestNewTransportInvalid(t *testing.T) {
+	cases := []config.HTTPConfig{
+		{Proxy: "not a url"},
+		{Proxy: "ftp://proxy:21"},
+		{CABundle: "does-not-exist.pem"},
+		{ClientCert: "cert.pem"},
+	}
+
+	for _, c := range cases {
+		_, err := NewTransport(&config.Config{HTTP: c}, nil)
+		assert.Error(t, err, "%+v", c)
+	}
+}
+
+func TestNewTransportProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Requests through a proxy carry the full URL
+		assert.Equal(t, "example.invalid", r.URL.Host)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	httpClient, err := NewHTTPClient(&config.Config{HTTP: config.HTTPConfig{Proxy: proxy.URL}}, nil)
+	require.NoError(t, err)
+
+	resp, err := httpClient.Get("http://example.invalid/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestNewTransportTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caBundle := filepath.Join(dir, "ca.pem")
+	writePEM(t, caBundle, "CERTIFICATE", srv.Certificate().Raw)
+
+	clientCert, clientKey := writeClientCert(t, dir)
+
+	get := func(c config.HTTPConfig) error {
+		httpClient, err := NewHTTPClient(&config.Config{HTTP: c}, nil)
+		require.NoError(t, err)
+
+		resp, err := httpClient.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		return err
+	}
+
+	// The server certificate is only trusted with the CA bundle
+	assert.Error(t, get(config.HTTPConfig{ClientCert: clientCert, ClientKey: clientKey}))
+
+	// The server requires a client certificate
+	assert.Error(t, get(config.HTTPConfig{CABundle: caBundle}))
+
+	assert.NoError(t, get(config.HTTPConfig{CABundle: caBundle, ClientCert: clientCert, ClientKey: clientKey}))
+}
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+}
+
+// writeClientCert writes a self-signed client certificate and its key
+func writeClientCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, "client.pem")
+	keyPath := filepath.Join(dir, "client-key.pem")
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+
+	return certPath, keyPath
+}
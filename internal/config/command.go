@@ -24,8 +24,13 @@ var cmdSet = &cobra.Command{
 	Long: `Set a configuration value
 
 The set command sets a persistent configuration value for the New Relic CLI.
+The value is checked against the type and allowed values of the key, which can
+be seen with the describe command.  Keys within a section are separated by a
+dot, and lists are given as comma separated values.
 `,
-	Example: "newrelic config set --key <key> --value <value>",
+	Example: `newrelic config set --key <key> --value <value>
+newrelic config set --key output.format --value YAML
+newrelic config set --key install.recipePaths --value recipes/a.yml,recipes/b.yml`,
 	Run: func(cmd *cobra.Command, args []string) {
 		WithConfig(func(cfg *Config) {
 			utils.LogIfError(cfg.Set(key, value))
@@ -39,11 +44,36 @@ var cmdGet = &cobra.Command{
 	Long: `Get a configuration value
 
 The get command gets a persistent configuration value for the New Relic CLI.
+Given the name of a section, such as install, all of its values are shown.
 `,
 	Example: "newrelic config get --key <key>",
 	Run: func(cmd *cobra.Command, args []string) {
 		WithConfig(func(cfg *Config) {
-			cfg.Get(key)
+			utils.LogIfError(cfg.Get(key))
+		})
+	},
+}
+
+var cmdDescribe = &cobra.Command{
+	Use:   "describe [key]",
+	Short: "Describe the available configuration keys",
+	Long: `Describe the available configuration keys
+
+The describe command shows the type, default value, allowed values and a
+description of a configuration key.  Given the name of a section, such as
+output, all of its keys are described.  All keys are described when none is
+given.
+`,
+	Example: "newrelic config describe output.format",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		WithConfig(func(cfg *Config) {
+			var k string
+			if len(args) > 0 {
+				k = args[0]
+			}
+
+			utils.LogIfError(cfg.Describe(k))
 		})
 	},
 }
@@ -54,6 +84,8 @@ var cmdList = &cobra.Command{
 	Long: `List the current configuration values
 
 The list command lists all persistent configuration values for the New Relic CLI.
+Values set in a .newrelic.yml file in the current directory or one of its parents
+override the global configuration, and the source of each value is shown.
 `,
 	Example: "newrelic config list",
 	Run: func(cmd *cobra.Command, args []string) {
@@ -98,6 +130,8 @@ func init() {
 	cmdGet.Flags().StringVarP(&key, "key", "k", "", "the key to get")
 	utils.LogIfError(cmdGet.MarkFlagRequired("key"))
 
+	Command.AddCommand(cmdDescribe)
+
 	Command.AddCommand(cmdDelete)
 	cmdDelete.Flags().StringVarP(&key, "key", "k", "", "the key to delete")
 	utils.LogIfError(cmdDelete.MarkFlagRequired("key"))
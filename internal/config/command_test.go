@@ -37,3 +37,10 @@ func TestCmdSet(t *testing.T) {
 	testcobra.CheckCobraMetadata(t, cmdSet)
 	testcobra.CheckCobraRequiredFlags(t, cmdSet, []string{"key", "value"})
 }
+
+func TestCmdDescribe(t *testing.T) {
+	assert.Equal(t, "describe", cmdDescribe.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdDescribe)
+	testcobra.CheckCobraRequiredFlags(t, cmdDescribe, []string{})
+}
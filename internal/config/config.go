@@ -5,6 +5,7 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/newrelic/newrelic-cli/internal/utils"
 
@@ -26,6 +27,18 @@ const (
 	// DefaultEnvPrefix is used when reading environment variables
 	DefaultEnvPrefix = "NEW_RELIC_CLI"
 
+	// DefaultAuditLogFile is the default name of the audit log in the config directory
+	DefaultAuditLogFile = "audit.jsonl"
+
+	// DefaultRetryMaxAttempts is the default number of attempts for each request
+	DefaultRetryMaxAttempts = 4
+
+	// DefaultRetryMinBackoff is the default wait before the first retry
+	DefaultRetryMinBackoff = time.Second
+
+	// DefaultRetryMaxBackoff is the default longest wait between retries
+	DefaultRetryMaxBackoff = 30 * time.Second
+
 	globalScopeIdentifier = "*"
 )
 
@@ -38,12 +51,46 @@ var (
 
 // Config contains the main CLI configuration
 type Config struct {
-	LogLevel           string  `mapstructure:"logLevel"`           // LogLevel for verbose output
-	PluginDir          string  `mapstructure:"pluginDir"`          // PluginDir is the directory where plugins will be installed
-	SendUsageData      Ternary `mapstructure:"sendUsageData"`      // SendUsageData enables sending usage statistics to New Relic
-	PreReleaseFeatures Ternary `mapstructure:"preReleaseFeatures"` // PreReleaseFeatures enables display on features within the CLI that are announced but not generally available to customers
+	LogLevel           string        `mapstructure:"logLevel"`           // LogLevel for verbose output
+	PluginDir          string        `mapstructure:"pluginDir"`          // PluginDir is the directory where plugins will be installed
+	AuditLogFile       string        `mapstructure:"auditLogFile"`       // AuditLogFile is the file changes made with the CLI are logged to
+	SendUsageData      Ternary       `mapstructure:"sendUsageData"`      // SendUsageData enables sending usage statistics to New Relic
+	PreReleaseFeatures Ternary       `mapstructure:"preReleaseFeatures"` // PreReleaseFeatures enables display on features within the CLI that are announced but not generally available to customers
+	Profile            string        `mapstructure:"profile"`            // Profile is the profile to use when none is selected with --profile or NEW_RELIC_PROFILE
+	AccountID          int           `mapstructure:"accountId"`          // AccountID overrides the account ID of the profile in use
+	Output             OutputConfig  `mapstructure:"output"`             // Output contains the output settings
+	Install            InstallConfig `mapstructure:"install"`            // Install contains the settings for the install command
+	HTTP               HTTPConfig    `mapstructure:"http"`               // HTTP contains the settings for requests to New Relic
 
 	configDir string
+
+	// sources records where keys set outside of the global config came from
+	sources map[string]string
+}
+
+// OutputConfig contains the output settings
+type OutputConfig struct {
+	Format string `mapstructure:"format"` // Format is the output format to use when --format is not given
+	Plain  bool   `mapstructure:"plain"`  // Plain disables output formatting when --plain is not given
+}
+
+// InstallConfig contains the settings for the install command
+type InstallConfig struct {
+	RecipePaths []string `mapstructure:"recipePaths"` // RecipePaths are the recipe files to install when no --recipePath is given
+	RecipeNames []string `mapstructure:"recipeNames"` // RecipeNames are the recipes to install when no --recipe is given
+}
+
+// HTTPConfig contains the settings for requests to New Relic
+type HTTPConfig struct {
+	RetryMaxAttempts int           `mapstructure:"retryMaxAttempts"` // RetryMaxAttempts is the number of attempts made for each request, 1 disables retries
+	RetryMinBackoff  time.Duration `mapstructure:"retryMinBackoff"`  // RetryMinBackoff is the wait before the first retry
+	RetryMaxBackoff  time.Duration `mapstructure:"retryMaxBackoff"`  // RetryMaxBackoff is the longest wait between retries
+
+	Proxy              string `mapstructure:"proxy"`              // Proxy is the URL of the proxy for requests, instead of HTTPS_PROXY
+	CABundle           string `mapstructure:"caBundle"`           // CABundle is a PEM file of certificates to trust as well as the system ones
+	ClientCert         string `mapstructure:"clientCert"`         // ClientCert is the PEM certificate file for mutual TLS
+	ClientKey          string `mapstructure:"clientKey"`          // ClientKey is the PEM key file for ClientCert
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"` // InsecureSkipVerify disables verification of server certificates
 }
 
 // Value represents an instance of a configuration field.
@@ -51,6 +98,7 @@ type Value struct {
 	Name    string
 	Value   interface{}
 	Default interface{}
+	Source  string
 }
 
 // IsDefault returns true if the field's value is the default value.
@@ -59,7 +107,13 @@ func (c *Value) IsDefault() bool {
 		return strings.EqualFold(v, c.Default.(string))
 	}
 
-	return c.Value == c.Default
+	// Empty lists are the same whether or not they are nil
+	if v, ok := c.Value.([]string); ok && len(v) == 0 {
+		d, _ := c.Default.([]string)
+		return len(d) == 0
+	}
+
+	return reflect.DeepEqual(c.Value, c.Default)
 }
 
 func init() {
@@ -67,6 +121,11 @@ func init() {
 		LogLevel:           DefaultLogLevel,
 		SendUsageData:      TernaryValues.Unknown,
 		PreReleaseFeatures: TernaryValues.Unknown,
+		HTTP: HTTPConfig{
+			RetryMaxAttempts: DefaultRetryMaxAttempts,
+			RetryMinBackoff:  DefaultRetryMinBackoff,
+			RetryMaxBackoff:  DefaultRetryMaxBackoff,
+		},
 	}
 
 	cfgDir, err := utils.GetDefaultConfigDirectory()
@@ -76,6 +135,9 @@ func init() {
 
 	DefaultConfigDirectory = cfgDir
 	defaultConfig.PluginDir = DefaultConfigDirectory + "/plugins"
+	FieldByKey("pluginDir").Default = defaultConfig.PluginDir
+	defaultConfig.AuditLogFile = DefaultConfigDirectory + "/" + DefaultAuditLogFile
+	FieldByKey("auditLogFile").Default = defaultConfig.AuditLogFile
 }
 
 // LoadConfig loads the configuration from disk, substituting defaults
@@ -108,12 +170,12 @@ func (c *Config) List() {
 // Delete deletes a config value.
 // This has the effect of reverting the value back to its default.
 func (c *Config) Delete(key string) error {
-	defaultValue, err := c.getDefaultValue(key)
-	if err != nil {
-		return err
+	f := FieldByKey(key)
+	if f == nil {
+		return invalidKeyError(key)
 	}
 
-	err = c.set(key, defaultValue)
+	err := c.set(key, f.Default)
 	if err != nil {
 		return err
 	}
@@ -123,23 +185,56 @@ func (c *Config) Delete(key string) error {
 	return nil
 }
 
-// Get retrieves a config value.
-func (c *Config) Get(key string) {
+// Get retrieves a config value, or all of the values in a section.
+func (c *Config) Get(key string) error {
+	if len(fieldsInSection(key)) == 0 {
+		return invalidKeyError(key)
+	}
+
 	output.Text(c.getAll(key))
+
+	return nil
+}
+
+// Describe outputs the definition of a key, the keys in a section, or all
+// keys when none is given.
+func (c *Config) Describe(key string) error {
+	fields := fieldsInSection(key)
+	if len(fields) == 0 {
+		return invalidKeyError(key)
+	}
+
+	// Show durations as written, e.g. 1s, rather than in nanoseconds
+	described := make([]FieldDefinition, len(fields))
+	for i, f := range fields {
+		described[i] = *f
+		described[i].Default = fileValue(f.Default)
+	}
+
+	output.Text(described)
+
+	return nil
 }
 
-// Set is used to update a config value.
+// Set is used to update a config value.  The value is parsed according to
+// the type of the key in the schema.
 func (c *Config) Set(key string, value interface{}) error {
-	if !stringInStrings(key, validConfigKeys()) {
-		return fmt.Errorf("\"%s\" is not a valid key; Please use one of: %s", key, validConfigKeys())
+	f := FieldByKey(key)
+	if f == nil {
+		return invalidKeyError(key)
+	}
+
+	parsed, err := f.Parse(value)
+	if err != nil {
+		return err
 	}
 
-	err := c.set(key, value)
+	err = c.set(key, parsed)
 	if err != nil {
 		return err
 	}
 
-	output.Printf("%s set to %s\n", text.Bold.Sprint(key), text.FgCyan.Sprint(value))
+	output.Printf("%s set to %s\n", text.Bold.Sprint(key), text.FgCyan.Sprint(parsed))
 
 	return nil
 }
@@ -161,6 +256,21 @@ func load(configDir string) (*Config, error) {
 		config = Config{}
 	}
 
+	warnUnknownKeys(cfgViper.Sub(globalScopeIdentifier), cfgViper.ConfigFileUsed())
+
+	config.sources = map[string]string{}
+
+	// Validate the global values before the project ones are merged over them
+	err = validateFile(config, cfgViper.ConfigFileUsed())
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.applyProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	err = config.setDefaults()
 	if err != nil {
 		return nil, err
@@ -176,7 +286,7 @@ func load(configDir string) (*Config, error) {
 
 func (c *Config) createFile(path string, cfgViper *viper.Viper) error {
 	err := c.visitAllConfigFields(func(v *Value) error {
-		cfgViper.Set(globalScopeIdentifier+"."+v.Name, v.Value)
+		cfgViper.Set(globalScopeIdentifier+"."+v.Name, fileValue(v.Value))
 		return nil
 	})
 	if err != nil {
@@ -202,11 +312,17 @@ func (c *Config) getAll(key string) []Value {
 	values := []Value{}
 
 	err := c.visitAllConfigFields(func(v *Value) error {
-		// Return early if name was supplied and doesn't match
-		if key != "" && key != v.Name {
+		// Return early if name was supplied and doesn't match the key or its section
+		if key != "" && key != v.Name && !strings.HasPrefix(v.Name, key+".") {
 			return nil
 		}
 
+		v.Source = c.source(v)
+
+		// Show durations as written, e.g. 1s, rather than in nanoseconds
+		v.Value = fileValue(v.Value)
+		v.Default = fileValue(v.Default)
+
 		values = append(values, *v)
 
 		return nil
@@ -224,7 +340,7 @@ func (c *Config) set(key string, value interface{}) error {
 		return err
 	}
 
-	cfgViper.Set(globalScopeIdentifier+"."+key, value)
+	cfgViper.Set(globalScopeIdentifier+"."+key, fileValue(value))
 
 	allScopes, err := unmarshalAllScopes(cfgViper)
 	if err != nil {
@@ -255,7 +371,9 @@ func (c *Config) set(key string, value interface{}) error {
 
 	path := fmt.Sprintf("%s/%s.%s", c.configDir, DefaultConfigName, DefaultConfigType)
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		createErr := c.createFile(path, cfgViper)
+		// Only the global values belong in the new file, not project ones
+		config.configDir = c.configDir
+		createErr := config.createFile(path, cfgViper)
 		if createErr != nil {
 			return createErr
 		}
@@ -270,35 +388,12 @@ func (c *Config) set(key string, value interface{}) error {
 	return nil
 }
 
-func (c *Config) getDefaultValue(key string) (interface{}, error) {
-	var dv interface{}
-	var found bool
-
-	err := c.visitAllConfigFields(func(v *Value) error {
-		if key == v.Name {
-			dv = v.Default
-			found = true
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		return dv, err
-	}
-
-	if found {
-		return dv, nil
-	}
-
-	return nil, fmt.Errorf("failed to locate default value for %s", key)
-}
-
 func (c *Config) applyOverrides() error {
 	log.Debug("setting config overrides")
 
 	if v := os.Getenv("NEW_RELIC_CLI_PRERELEASEFEATURES"); v != "" {
 		c.PreReleaseFeatures = Ternary(v)
+		c.setSource("preReleaseFeatures", sourceEnvironment)
 	}
 
 	return nil
@@ -320,20 +415,7 @@ func (c *Config) setDefaults() error {
 
 func (c *Config) validate() error {
 	err := c.visitAllConfigFields(func(v *Value) error {
-		switch k := strings.ToLower(v.Name); k {
-		case "loglevel":
-			validValues := []string{"Info", "Debug", "Trace", "Warn", "Error"}
-			if !stringInStringsIgnoreCase(v.Value.(string), validValues) {
-				return fmt.Errorf("\"%s\" is not a valid %s value; Please use one of: %s", v.Value, v.Name, validValues)
-			}
-		case "sendusagedata", "prereleasefeatures":
-			err := (v.Value.(Ternary)).Valid()
-			if err != nil {
-				return fmt.Errorf("invalid value for '%s': %s", v.Name, err)
-			}
-		}
-
-		return nil
+		return FieldByKey(v.Name).Validate(v.Value)
 	})
 
 	if err != nil {
@@ -343,28 +425,31 @@ func (c *Config) validate() error {
 	return nil
 }
 
-func (c *Config) visitAllConfigFields(f func(*Value) error) error {
-	cfgType := reflect.TypeOf(*c)
-	cfgValue := reflect.ValueOf(*c)
-	defaultCfgValue := reflect.ValueOf(*defaultConfig)
+// validateFile validates the values read from a config file on their own,
+// so errors point at the file they came from.
+func validateFile(c Config, path string) error {
+	if err := c.setDefaults(); err != nil {
+		return err
+	}
 
-	// Iterate through the fields in the struct
-	for i := 0; i < cfgType.NumField(); i++ {
-		field := cfgType.Field(i)
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("invalid config file %s: %s", path, err)
+	}
 
-		// Skip unexported fields
-		if field.PkgPath != "" {
-			continue
-		}
+	return nil
+}
 
-		name := field.Tag.Get("mapstructure")
-		value := cfgValue.Field(i).Interface()
-		defaultValue := defaultCfgValue.Field(i).Interface()
+func (c *Config) visitAllConfigFields(f func(*Value) error) error {
+	for _, field := range Schema {
+		value, err := c.value(field.Key)
+		if err != nil {
+			return err
+		}
 
-		err := f(&Value{
-			Name:    name,
+		err = f(&Value{
+			Name:    field.Key,
 			Value:   value,
-			Default: defaultValue,
+			Default: field.Default,
 		})
 
 		if err != nil {
@@ -407,26 +492,30 @@ func readConfig(configDir string) (*viper.Viper, error) {
 }
 
 func validConfigKeys() []string {
-	var keys []string
+	keys := make([]string, 0, len(Schema))
 
-	cfgType := reflect.TypeOf(Config{})
-	for i := 0; i < cfgType.NumField(); i++ {
-		field := cfgType.Field(i)
-		name := field.Tag.Get("mapstructure")
-		keys = append(keys, name)
+	for _, f := range Schema {
+		keys = append(keys, f.Key)
 	}
 
 	return keys
 }
 
-func stringInStrings(s string, ss []string) bool {
-	for _, v := range ss {
-		if v == s {
-			return true
-		}
+func invalidKeyError(key string) error {
+	return fmt.Errorf("\"%s\" is not a valid key; Please use one of: %s", key, validConfigKeys())
+}
+
+// warnUnknownKeys warns about keys in a config file which are not in the schema
+func warnUnknownKeys(cfgViper *viper.Viper, path string) {
+	if cfgViper == nil {
+		return
 	}
 
-	return false
+	for _, k := range cfgViper.AllKeys() {
+		if configKey(k) == "" {
+			log.Warnf("ignoring unknown key %q in %s", k, path)
+		}
+	}
 }
 
 // Function ignores the case
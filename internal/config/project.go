@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	// ProjectConfigFile is the name of the per-project configuration file,
+	// found by searching the working directory and its parents.
+	ProjectConfigFile = ".newrelic.yml"
+
+	sourceDefault     = "default"
+	sourceEnvironment = "environment"
+)
+
+// applyProjectConfig sets the keys given in the nearest project config
+// file over the global configuration.
+func (c *Config) applyProjectConfig() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Debugf("unable to determine working directory: %s", err)
+		return nil
+	}
+
+	path := findProjectConfig(cwd)
+	if path == "" {
+		return nil
+	}
+
+	project, keys, err := readProjectConfig(path)
+	if err != nil {
+		return err
+	}
+
+	if err = validateFile(*project, path); err != nil {
+		return err
+	}
+
+	log.Debugf("merging project config from %s", path)
+
+	// Only the keys the file sets are copied, so they may be set to false,
+	// zero or empty over the global configuration
+	for _, k := range keys {
+		dst, err := c.field(k)
+		if err != nil {
+			return err
+		}
+
+		src, err := project.field(k)
+		if err != nil {
+			return err
+		}
+
+		dst.Set(src)
+		c.setSource(k, path)
+	}
+
+	return nil
+}
+
+// findProjectConfig returns the path of the project config file in dir or
+// its nearest parent, or an empty string if there is none.
+func findProjectConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, ProjectConfigFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// readProjectConfig reads a project config file, returning the keys it sets
+func readProjectConfig(path string) (*Config, []string, error) {
+	cfgViper := viper.New()
+	cfgViper.SetConfigFile(path)
+	cfgViper.SetConfigType("yaml")
+
+	if err := cfgViper.ReadInConfig(); err != nil {
+		return nil, nil, fmt.Errorf("error parsing project config %s: %v", path, err)
+	}
+
+	keys := []string{}
+
+	// Viper lower cases keys, so find the names they were declared with
+	for _, k := range cfgViper.AllKeys() {
+		name := configKey(k)
+		if name == "" {
+			log.Warnf("ignoring unknown key %q in %s", k, path)
+			continue
+		}
+
+		// A cloned repository must not be able to redirect requests or
+		// plugins, so only settings scoped to the project are allowed
+		if !projectConfigKey(name) {
+			return nil, nil, fmt.Errorf("%q may not be set in project config %s, only profile, accountId, output.* and install.* are allowed", name, path)
+		}
+
+		keys = append(keys, name)
+	}
+
+	project := Config{}
+	if err := cfgViper.Unmarshal(&project); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal project config %s: %v", path, err)
+	}
+
+	// Recipe files are relative to the project, not the working directory
+	for i, r := range project.Install.RecipePaths {
+		if !filepath.IsAbs(r) && !strings.Contains(r, "://") {
+			project.Install.RecipePaths[i] = filepath.Join(filepath.Dir(path), r)
+		}
+	}
+
+	return &project, keys, nil
+}
+
+// configKey returns the declared name of a config key, ignoring case
+func configKey(key string) string {
+	for _, k := range validConfigKeys() {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+
+	return ""
+}
+
+// projectConfigKey reports whether a key may be set in a project config file
+func projectConfigKey(key string) bool {
+	return key == "profile" || key == "accountId" ||
+		strings.HasPrefix(key, "output.") || strings.HasPrefix(key, "install.")
+}
+
+func (c *Config) setSource(key string, source string) {
+	if c.sources == nil {
+		c.sources = map[string]string{}
+	}
+
+	c.sources[key] = source
+}
+
+// source describes where a value came from: a project config file, the
+// environment, the global config file or the defaults.
+func (c *Config) source(v *Value) string {
+	if s, ok := c.sources[v.Name]; ok {
+		return s
+	}
+
+	if v.IsDefault() {
+		return sourceDefault
+	}
+
+	return filepath.Join(c.configDir, DefaultConfigName+"."+DefaultConfigType)
+}
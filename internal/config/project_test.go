@@ -0,0 +1,123 @@
+// +build unit
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-project")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Resolve symlinks so paths match the working directory
+	dir, err = filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+
+	configDir := filepath.Join(dir, "config")
+	subDir := filepath.Join(dir, "project", "src", "app")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+
+	projectFile := filepath.Join(dir, "project", ProjectConfigFile)
+	require.NoError(t, ioutil.WriteFile(projectFile, []byte(`
+profile: staging
+accountId: 12345
+output:
+  format: yaml
+install:
+  recipePaths:
+    - recipes/app.yml
+    - https://example.com/recipe.yml
+`), 0644))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(wd) // nolint:errcheck
+
+	// No project config outside of the project
+	require.NoError(t, os.Chdir(dir))
+	assert.Equal(t, "", findProjectConfig(dir))
+
+	c, err := LoadConfig(configDir)
+	require.NoError(t, err)
+	assert.Equal(t, "", c.Profile)
+
+	// The project config is found from any directory within it
+	require.NoError(t, os.Chdir(subDir))
+	assert.Equal(t, projectFile, findProjectConfig(subDir))
+
+	c, err = LoadConfig(configDir)
+	require.NoError(t, err)
+	assert.Equal(t, "staging", c.Profile)
+	assert.Equal(t, 12345, c.AccountID)
+	assert.Equal(t, "yaml", c.Output.Format)
+	assert.Equal(t, DefaultLogLevel, c.LogLevel)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "project", "recipes", "app.yml"),
+		"https://example.com/recipe.yml",
+	}, c.Install.RecipePaths)
+
+	sources := map[string]string{}
+	for _, v := range c.getAll("") {
+		sources[v.Name] = v.Source
+	}
+
+	assert.Equal(t, projectFile, sources["profile"])
+	assert.Equal(t, projectFile, sources["accountId"])
+	assert.Equal(t, projectFile, sources["output.format"])
+	assert.Equal(t, sourceDefault, sources["logLevel"])
+
+	// Invalid values are reported against the project file
+	require.NoError(t, ioutil.WriteFile(projectFile, []byte("output:\n  format: xml\n"), 0644))
+
+	_, err = LoadConfig(configDir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), projectFile)
+
+	// Settings which could redirect requests are refused
+	require.NoError(t, ioutil.WriteFile(projectFile, []byte("http:\n  proxy: http://localhost:8080\n"), 0644))
+
+	_, err = LoadConfig(configDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http.proxy")
+}
+
+func TestProjectConfigOverridesWithZeroValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-project")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dir, err = filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+
+	configDir := filepath.Join(dir, "config")
+	projectDir := filepath.Join(dir, "project")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(wd) // nolint:errcheck
+
+	require.NoError(t, os.Chdir(dir))
+
+	c, err := LoadConfig(configDir)
+	require.NoError(t, err)
+	require.NoError(t, c.Set("output.plain", true))
+	require.NoError(t, c.Set("accountId", 12345))
+
+	// A project can turn off or clear what the global config sets
+	require.NoError(t, ioutil.WriteFile(filepath.Join(projectDir, ProjectConfigFile), []byte("output:\n  plain: false\naccountId: 0\n"), 0644))
+	require.NoError(t, os.Chdir(projectDir))
+
+	c, err = LoadConfig(configDir)
+	require.NoError(t, err)
+	assert.False(t, c.Output.Plain)
+	assert.Equal(t, 0, c.AccountID)
+}
@@ -0,0 +1,327 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/newrelic/newrelic-cli/internal/output"
+)
+
+// FieldType is the type of a configuration value
+type FieldType string
+
+// Types of configuration values
+const (
+	StringType     FieldType = "string"
+	IntType        FieldType = "int"
+	BoolType       FieldType = "bool"
+	TernaryType    FieldType = "ternary"
+	StringListType FieldType = "stringList"
+	DurationType   FieldType = "duration"
+)
+
+// FieldDefinition describes a configuration key.  Keys within a nested
+// section are separated by a dot, for example output.format.
+type FieldDefinition struct {
+	Key           string      `json:"key" yaml:"key"`
+	Type          FieldType   `json:"type" yaml:"type"`
+	Description   string      `json:"description" yaml:"description"`
+	Default       interface{} `json:"default" yaml:"default"`
+	AllowedValues []string    `json:"allowedValues,omitempty" yaml:"allowedValues,omitempty"`
+}
+
+// Schema declares every configuration key.  It drives parsing, validation,
+// defaults and the config commands.
+var Schema = []*FieldDefinition{
+	{
+		Key:           "logLevel",
+		Type:          StringType,
+		Description:   "The log level for verbose output",
+		Default:       DefaultLogLevel,
+		AllowedValues: []string{"Info", "Debug", "Trace", "Warn", "Error"},
+	},
+	{
+		Key:         "pluginDir",
+		Type:        StringType,
+		Description: "The directory where plugins will be installed",
+	},
+	{
+		Key:         "auditLogFile",
+		Type:        StringType,
+		Description: "The file changes made with the CLI are logged to, see the audit command",
+	},
+	{
+		Key:           "sendUsageData",
+		Type:          TernaryType,
+		Description:   "Whether usage statistics are sent to New Relic",
+		Default:       TernaryValues.Unknown,
+		AllowedValues: ternaryValues(),
+	},
+	{
+		Key:           "preReleaseFeatures",
+		Type:          TernaryType,
+		Description:   "Whether features which are announced but not generally available are shown",
+		Default:       TernaryValues.Unknown,
+		AllowedValues: ternaryValues(),
+	},
+	{
+		Key:         "profile",
+		Type:        StringType,
+		Description: "The profile to use when none is selected with --profile or NEW_RELIC_PROFILE",
+		Default:     "",
+	},
+	{
+		Key:         "accountId",
+		Type:        IntType,
+		Description: "The account ID to use instead of the account ID of the profile in use, unless the profile is selected with --profile or NEW_RELIC_PROFILE",
+		Default:     0,
+	},
+	{
+		Key:           "output.format",
+		Type:          StringType,
+		Description:   "The output format to use when --format is not given",
+		Default:       "",
+		AllowedValues: strings.Split(output.FormatOptions(), ", "),
+	},
+	{
+		Key:         "output.plain",
+		Type:        BoolType,
+		Description: "Whether output is unformatted when --plain is not given",
+		Default:     false,
+	},
+	{
+		Key:         "install.recipePaths",
+		Type:        StringListType,
+		Description: "The recipe files to install when no --recipePath is given",
+		Default:     []string{},
+	},
+	{
+		Key:         "install.recipeNames",
+		Type:        StringListType,
+		Description: "The recipes to install when no --recipe is given",
+		Default:     []string{},
+	},
+	{
+		Key:         "http.retryMaxAttempts",
+		Type:        IntType,
+		Description: "The number of attempts made for requests which are rate limited or fail with a server or connection error, 1 disables retries.  Changes are only retried when rate limited or unable to connect",
+		Default:     DefaultRetryMaxAttempts,
+	},
+	{
+		Key:         "http.retryMinBackoff",
+		Type:        DurationType,
+		Description: "The wait before the first retry, doubling with each further retry",
+		Default:     DefaultRetryMinBackoff,
+	},
+	{
+		Key:         "http.retryMaxBackoff",
+		Type:        DurationType,
+		Description: "The longest wait between retries, unless the response asks for longer with Retry-After",
+		Default:     DefaultRetryMaxBackoff,
+	},
+	{
+		Key:         "http.proxy",
+		Type:        StringType,
+		Description: "The URL of the proxy for requests, used instead of HTTPS_PROXY and NO_PROXY",
+		Default:     "",
+	},
+	{
+		Key:         "http.caBundle",
+		Type:        StringType,
+		Description: "The path of a PEM file of CA certificates to trust as well as the system certificates",
+		Default:     "",
+	},
+	{
+		Key:         "http.clientCert",
+		Type:        StringType,
+		Description: "The path of a PEM client certificate for mutual TLS, requires http.clientKey",
+		Default:     "",
+	},
+	{
+		Key:         "http.clientKey",
+		Type:        StringType,
+		Description: "The path of the PEM private key for http.clientCert",
+		Default:     "",
+	},
+	{
+		Key:         "http.insecureSkipVerify",
+		Type:        BoolType,
+		Description: "Whether verification of server certificates is disabled, which is not secure",
+		Default:     false,
+	},
+}
+
+func ternaryValues() []string {
+	return []string{
+		TernaryValues.Allow.String(),
+		TernaryValues.Disallow.String(),
+		TernaryValues.Unknown.String(),
+	}
+}
+
+// FieldByKey returns the definition of a key, or nil if there is none
+func FieldByKey(key string) *FieldDefinition {
+	for _, f := range Schema {
+		if f.Key == key {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// fieldsInSection returns the definitions of a key, or of every key in a
+// section.  An empty key returns all definitions.
+func fieldsInSection(key string) []*FieldDefinition {
+	fields := []*FieldDefinition{}
+
+	for _, f := range Schema {
+		if key == "" || f.Key == key || strings.HasPrefix(f.Key, key+".") {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// Parse converts a value given on the command line to the key's type,
+// validating it.
+func (f *FieldDefinition) Parse(value interface{}) (interface{}, error) {
+	s := strings.TrimSpace(fmt.Sprint(value))
+
+	var parsed interface{}
+
+	switch f.Type {
+	case IntType:
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("\"%s\" is not a valid %s value; an integer is required", s, f.Key)
+		}
+		parsed = i
+	case BoolType:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("\"%s\" is not a valid %s value; Please use one of: [true false]", s, f.Key)
+		}
+		parsed = b
+	case DurationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("\"%s\" is not a valid %s value; a duration such as 500ms or 2s is required", s, f.Key)
+		}
+		parsed = d
+	case TernaryType:
+		parsed = Ternary(strings.ToUpper(s))
+	case StringListType:
+		list := []string{}
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				list = append(list, v)
+			}
+		}
+		parsed = list
+	default:
+		parsed = s
+	}
+
+	if err := f.Validate(parsed); err != nil {
+		return nil, err
+	}
+
+	return parsed, nil
+}
+
+// Validate checks a value has the key's type and is one of its allowed values
+func (f *FieldDefinition) Validate(value interface{}) error {
+	switch f.Type {
+	case IntType:
+		i, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("invalid value for '%s': expected an integer, got %T", f.Key, value)
+		}
+		if i < 0 {
+			return fmt.Errorf("\"%d\" is not a valid %s value; it cannot be negative", i, f.Key)
+		}
+	case DurationType:
+		d, ok := value.(time.Duration)
+		if !ok {
+			return fmt.Errorf("invalid value for '%s': expected a duration, got %T", f.Key, value)
+		}
+		if d < 0 {
+			return fmt.Errorf("\"%s\" is not a valid %s value; it cannot be negative", d, f.Key)
+		}
+	case BoolType:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("invalid value for '%s': expected true or false, got %T", f.Key, value)
+		}
+	case TernaryType:
+		t, ok := value.(Ternary)
+		if !ok {
+			return fmt.Errorf("invalid value for '%s': expected one of %s, got %T", f.Key, f.AllowedValues, value)
+		}
+		if err := t.Valid(); err != nil {
+			return fmt.Errorf("invalid value for '%s': %s", f.Key, err)
+		}
+	case StringListType:
+		if _, ok := value.([]string); !ok {
+			return fmt.Errorf("invalid value for '%s': expected a list of strings, got %T", f.Key, value)
+		}
+	default:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value for '%s': expected a string, got %T", f.Key, value)
+		}
+		if s != "" && len(f.AllowedValues) > 0 && !stringInStringsIgnoreCase(s, f.AllowedValues) {
+			return fmt.Errorf("\"%s\" is not a valid %s value; Please use one of: %s", s, f.Key, f.AllowedValues)
+		}
+	}
+
+	return nil
+}
+
+// field returns the struct field holding a key, following the mapstructure
+// tags of each nested section.
+func (c *Config) field(key string) (reflect.Value, error) {
+	v := reflect.ValueOf(c).Elem()
+
+	for _, name := range strings.Split(key, ".") {
+		t := v.Type()
+		found := false
+
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath == "" && t.Field(i).Tag.Get("mapstructure") == name {
+				v = v.Field(i)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return reflect.Value{}, fmt.Errorf("config key %s has no field", key)
+		}
+	}
+
+	return v, nil
+}
+
+// fileValue converts a value to the form it is written to the config file in
+func fileValue(value interface{}) interface{} {
+	if d, ok := value.(time.Duration); ok {
+		return d.String()
+	}
+
+	return value
+}
+
+// value returns the value of a key
+func (c *Config) value(key string) (interface{}, error) {
+	v, err := c.field(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Interface(), nil
+}
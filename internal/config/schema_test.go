@@ -0,0 +1,91 @@
+// +build unit
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaMatchesConfig(t *testing.T) {
+	c := Config{}
+
+	for _, f := range Schema {
+		v, err := c.value(f.Key)
+		require.NoError(t, err, f.Key)
+		assert.NoError(t, f.Validate(f.Default), f.Key)
+
+		// Defaults must have the same type as the field holding the key
+		assert.IsType(t, f.Default, v, f.Key)
+	}
+}
+
+func TestFieldDefinitionParse(t *testing.T) {
+	cases := []struct {
+		key      string
+		value    string
+		expected interface{}
+	}{
+		{"logLevel", "debug", "debug"},
+		{"accountId", "12345", 12345},
+		{"output.plain", "true", true},
+		{"output.format", "YAML", "YAML"},
+		{"sendUsageData", "allow", TernaryValues.Allow},
+		{"install.recipeNames", "a, b,,c", []string{"a", "b", "c"}},
+	}
+
+	for _, tc := range cases {
+		parsed, err := FieldByKey(tc.key).Parse(tc.value)
+		require.NoError(t, err, tc.key)
+		assert.Equal(t, tc.expected, parsed, tc.key)
+	}
+
+	invalid := map[string]string{
+		"logLevel":      "verbose",
+		"accountId":     "abc",
+		"output.plain":  "maybe",
+		"output.format": "xml",
+		"sendUsageData": "sometimes",
+	}
+
+	for k, v := range invalid {
+		_, err := FieldByKey(k).Parse(v)
+		assert.Error(t, err, k)
+	}
+
+	_, err := FieldByKey("accountId").Parse("-1")
+	assert.Error(t, err)
+}
+
+func TestFieldsInSection(t *testing.T) {
+	assert.Len(t, fieldsInSection(""), len(Schema))
+	assert.Len(t, fieldsInSection("install"), 2)
+	assert.Len(t, fieldsInSection("output.format"), 1)
+	assert.Empty(t, fieldsInSection("out"))
+	assert.Nil(t, FieldByKey("install"))
+}
+
+func TestLoadConfigInvalidValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-schema")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, DefaultConfigName+"."+DefaultConfigType)
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"*":{"logLevel":"loud"}}`), 0644))
+
+	_, err = LoadConfig(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), path)
+	assert.Contains(t, err.Error(), "logLevel")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"*":{"logLevel":"info","output":{"plain":true}}}`), 0644))
+
+	c, err := LoadConfig(dir)
+	require.NoError(t, err)
+	assert.True(t, c.Output.Plain)
+}
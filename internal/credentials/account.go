@@ -0,0 +1,45 @@
+package credentials
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+// AccountIDFlagName is the name of the flag selecting the account to use
+const AccountIDFlagName = "accountId"
+
+// accountIDFlag holds the value of the --accountId flag of whichever
+// command is running
+var accountIDFlag int
+
+// AddAccountIDFlag adds an optional --accountId flag to the flag set.  Use
+// RequireAccountID to read the account ID it selects.
+func AddAccountIDFlag(flags *pflag.FlagSet, usage string) {
+	flags.IntVarP(&accountIDFlag, AccountIDFlagName, "a", 0, usage+", defaults to the account ID of the profile in use")
+}
+
+// AccountID returns the account ID given with --accountId, falling back to
+// NEW_RELIC_ACCOUNT_ID, the account ID in the CLI configuration and then
+// the account ID of the profile in use.  Zero is returned if none is set.
+func AccountID() int {
+	if accountIDFlag != 0 {
+		return accountIDFlag
+	}
+
+	if p := DefaultProfile(); p != nil {
+		return p.AccountID
+	}
+
+	return 0
+}
+
+// RequireAccountID returns the account ID to use as AccountID does, exiting
+// with an error if there is none.
+func RequireAccountID() int {
+	accountID := AccountID()
+	if accountID == 0 {
+		log.Fatal("an account ID is required, use the --accountId flag, set NEW_RELIC_ACCOUNT_ID or add one to your profile")
+	}
+
+	return accountID
+}
@@ -0,0 +1,32 @@
+// +build unit
+
+package credentials
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountID(t *testing.T) {
+	defer SetProfileOverride("")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddAccountIDFlag(flags, "the account ID")
+
+	// Falls back to the profile in use
+	SetDefaultProfile(Profile{AccountID: 12345})
+	require.NoError(t, flags.Parse([]string{}))
+	assert.Equal(t, 12345, AccountID())
+
+	// But the flag wins
+	require.NoError(t, flags.Parse([]string{"--accountId", "67890"}))
+	assert.Equal(t, 67890, AccountID())
+	assert.Equal(t, 67890, RequireAccountID())
+
+	require.NoError(t, flags.Parse([]string{"-a", "0"}))
+	SetDefaultProfile(Profile{})
+	assert.Equal(t, 0, AccountID())
+}
@@ -0,0 +1,353 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+const bundleVersion = 1
+
+// Conflict handling modes when importing a profile which already exists
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// Actions taken for each profile in an import
+const (
+	ImportAdd       = "add"
+	ImportSkip      = "skip"
+	ImportOverwrite = "overwrite"
+	ImportRename    = "rename"
+	ImportUnchanged = "unchanged"
+)
+
+// Bundle is a set of profiles which can be exported and imported elsewhere.
+// The keys of a redacted bundle were left out rather than being empty.
+type Bundle struct {
+	Version        int                      `json:"version" yaml:"version"`
+	Redacted       bool                     `json:"redacted,omitempty" yaml:"redacted,omitempty"`
+	DefaultProfile string                   `json:"defaultProfile,omitempty" yaml:"defaultProfile,omitempty"`
+	Profiles       map[string]BundleProfile `json:"profiles" yaml:"profiles"`
+}
+
+// BundleProfile is a single profile within a bundle
+type BundleProfile struct {
+	Region            string `json:"region,omitempty" yaml:"region,omitempty"`
+	AccountID         int    `json:"accountID,omitempty" yaml:"accountID,omitempty"`
+	APIKey            string `json:"apiKey,omitempty" yaml:"apiKey,omitempty"`
+	InsightsInsertKey string `json:"insightsInsertKey,omitempty" yaml:"insightsInsertKey,omitempty"`
+	LicenseKey        string `json:"licenseKey,omitempty" yaml:"licenseKey,omitempty"`
+	CredentialProcess string `json:"credentialProcess,omitempty" yaml:"credentialProcess,omitempty"`
+	Proxy             string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	CABundle          string `json:"caBundle,omitempty" yaml:"caBundle,omitempty"`
+	ClientCert        string `json:"clientCert,omitempty" yaml:"clientCert,omitempty"`
+	ClientKey         string `json:"clientKey,omitempty" yaml:"clientKey,omitempty"`
+}
+
+// ImportChange describes what importing a profile from a bundle does
+type ImportChange struct {
+	Profile string   `json:"profile"`
+	Action  string   `json:"action"`
+	Target  string   `json:"target"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+func bundleProfileFrom(p Profile) BundleProfile {
+	return BundleProfile{
+		Region:            strings.ToLower(p.Region),
+		AccountID:         p.AccountID,
+		APIKey:            p.APIKey,
+		InsightsInsertKey: p.InsightsInsertKey,
+		LicenseKey:        p.LicenseKey,
+		CredentialProcess: p.CredentialProcess,
+		Proxy:             p.Proxy,
+		CABundle:          p.CABundle,
+		ClientCert:        p.ClientCert,
+		ClientKey:         p.ClientKey,
+	}
+}
+
+func (b BundleProfile) profile() Profile {
+	return Profile{
+		Region:            strings.ToUpper(b.Region),
+		AccountID:         b.AccountID,
+		APIKey:            b.APIKey,
+		InsightsInsertKey: b.InsightsInsertKey,
+		LicenseKey:        b.LicenseKey,
+		CredentialProcess: b.CredentialProcess,
+		Proxy:             b.Proxy,
+		CABundle:          b.CABundle,
+		ClientCert:        b.ClientCert,
+		ClientKey:         b.ClientKey,
+	}
+}
+
+// Export bundles the named profiles, or all profiles when none are given.
+// Keys held in a secret store are included unless redact is set.  Keys
+// from a credential process are never included, only the command itself.
+func (c *Credentials) Export(names []string, redact bool) (*Bundle, error) {
+	if len(names) == 0 {
+		for name := range c.Profiles {
+			names = append(names, name)
+		}
+	}
+
+	b := &Bundle{
+		Version:  bundleVersion,
+		Redacted: redact,
+		Profiles: map[string]BundleProfile{},
+	}
+
+	for _, name := range names {
+		p, ok := c.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("profile with name %s not found", name)
+		}
+
+		if redact {
+			p = p.withSecrets(Secrets{})
+		} else {
+			var err error
+			if p, err = c.loadStoredSecrets(name, p); err != nil {
+				return nil, err
+			}
+		}
+
+		b.Profiles[name] = bundleProfileFrom(p)
+
+		if name == c.DefaultProfile {
+			b.DefaultProfile = name
+		}
+	}
+
+	return b, nil
+}
+
+// Encrypt seals the bundle with a passphrase read from the environment or
+// the terminal.  The result can be read back with ReadBundle.
+func (b *Bundle) Encrypt() (interface{}, error) {
+	plain, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, err := readPassphrase("Bundle passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+
+	return sealData(plain, passphrase)
+}
+
+// ReadBundle reads a JSON or YAML profile bundle, decrypting it if needed.
+func ReadBundle(path string) (*Bundle, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading bundle %s: %s", path, err)
+	}
+
+	var enc encryptedData
+	if err = json.Unmarshal(content, &enc); err == nil && len(enc.Data) > 0 {
+		passphrase, err := readPassphrase("Bundle passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+
+		if content, err = openData(&enc, passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	var b Bundle
+	if err = yaml.Unmarshal(content, &b); err != nil {
+		return nil, fmt.Errorf("error parsing bundle %s: %s", path, err)
+	}
+
+	if b.Version != bundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+
+	return &b, nil
+}
+
+// PlanImport works out what importing the bundle would do, resolving
+// profiles which already exist with the given conflict mode.
+func (c *Credentials) PlanImport(b *Bundle, onConflict string) ([]ImportChange, error) {
+	switch onConflict {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return nil, fmt.Errorf("unknown conflict mode %q, valid values are %s, %s and %s", onConflict, ConflictSkip, ConflictOverwrite, ConflictRename)
+	}
+
+	names := make([]string, 0, len(b.Profiles))
+	for name := range b.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	plan := []ImportChange{}
+	taken := map[string]bool{}
+
+	for _, name := range names {
+		change := ImportChange{Profile: name, Target: name, Action: ImportAdd}
+
+		existing, ok := c.Profiles[name]
+		if ok {
+			existing, err := c.loadStoredSecrets(name, existing)
+			if err != nil {
+				return nil, err
+			}
+
+			change.Changes = diffProfiles(existing, b.importedProfile(name, existing))
+
+			switch {
+			case len(change.Changes) == 0:
+				change.Action = ImportUnchanged
+			case onConflict == ConflictSkip:
+				change.Action = ImportSkip
+			case onConflict == ConflictOverwrite:
+				change.Action = ImportOverwrite
+			case onConflict == ConflictRename:
+				change.Action = ImportRename
+				change.Target = c.availableName(name, taken)
+				change.Changes = nil
+			}
+		}
+
+		taken[change.Target] = true
+		plan = append(plan, change)
+	}
+
+	return plan, nil
+}
+
+// Import applies an import plan, keeping the imported keys in the given
+// secret store when one is set.
+func (c *Credentials) Import(b *Bundle, plan []ImportChange, secretStore string) error {
+	for _, change := range plan {
+		switch change.Action {
+		case ImportAdd, ImportOverwrite, ImportRename:
+		default:
+			continue
+		}
+
+		p := b.Profiles[change.Profile].profile()
+
+		if b.Redacted && change.Action == ImportOverwrite {
+			existing, err := c.loadStoredSecrets(change.Target, c.Profiles[change.Target])
+			if err != nil {
+				return err
+			}
+
+			p = b.importedProfile(change.Profile, existing)
+		}
+
+		p.SecretStore = secretStore
+
+		// Overwritten profiles keep their secret store unless told otherwise
+		if secretStore == "" && change.Action == ImportOverwrite {
+			p.SecretStore = c.Profiles[change.Target].SecretStore
+		}
+
+		if p.APIKey == "" && p.CredentialProcess == "" {
+			log.Warnf("profile %s has no API key", change.Target)
+		}
+
+		if err := c.setProfile(change.Target, p); err != nil {
+			return err
+		}
+	}
+
+	if err := c.writeProfiles(); err != nil {
+		return err
+	}
+
+	if c.DefaultProfile != "" || b.DefaultProfile == "" {
+		return nil
+	}
+
+	for _, change := range plan {
+		if change.Profile == b.DefaultProfile && change.Action != ImportSkip {
+			return c.SetDefaultProfile(change.Target)
+		}
+	}
+
+	return nil
+}
+
+// importedProfile returns the profile imported over an existing one.  The
+// keys left out of a redacted bundle are kept from the existing profile.
+func (b *Bundle) importedProfile(name string, existing Profile) Profile {
+	p := b.Profiles[name].profile()
+	if b.Redacted {
+		p = p.withSecrets(existing.secrets())
+	}
+
+	return p
+}
+
+// availableName finds an unused name for a renamed profile
+func (c *Credentials) availableName(name string, taken map[string]bool) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if _, ok := c.Profiles[candidate]; !ok && !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// diffProfiles lists the settings which differ between two profiles,
+// without revealing the values of keys.
+func diffProfiles(from Profile, to Profile) []string {
+	changes := []string{}
+
+	if !strings.EqualFold(from.Region, to.Region) {
+		changes = append(changes, fmt.Sprintf("region: %s -> %s", from.Region, to.Region))
+	}
+
+	if from.AccountID != to.AccountID {
+		changes = append(changes, fmt.Sprintf("accountID: %d -> %d", from.AccountID, to.AccountID))
+	}
+
+	settings := []struct {
+		name     string
+		from, to string
+	}{
+		{"credentialProcess", from.CredentialProcess, to.CredentialProcess},
+		{"proxy", from.Proxy, to.Proxy},
+		{"caBundle", from.CABundle, to.CABundle},
+		{"clientCert", from.ClientCert, to.ClientCert},
+		{"clientKey", from.ClientKey, to.ClientKey},
+	}
+
+	for _, s := range settings {
+		if s.from != s.to {
+			changes = append(changes, fmt.Sprintf("%s: %q -> %q", s.name, s.from, s.to))
+		}
+	}
+
+	keys := []struct {
+		name     string
+		from, to string
+	}{
+		{"apiKey", from.APIKey, to.APIKey},
+		{"insightsInsertKey", from.InsightsInsertKey, to.InsightsInsertKey},
+		{"licenseKey", from.LicenseKey, to.LicenseKey},
+	}
+
+	for _, k := range keys {
+		if k.from != k.to {
+			changes = append(changes, k.name+": changed")
+		}
+	}
+
+	return changes
+}
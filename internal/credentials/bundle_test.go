@@ -0,0 +1,155 @@
+// +build unit
+
+package credentials
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestExportImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-bundle")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := &Credentials{
+		DefaultProfile: "prod",
+		Profiles: map[string]Profile{
+			"prod":    {APIKey: "prodAPIKey", Region: "US", AccountID: 1},
+			"staging": {APIKey: "stagingAPIKey", Region: "EU", AccountID: 2},
+		},
+	}
+
+	bundle, err := source.Export(nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", bundle.DefaultProfile)
+	assert.Equal(t, "prodAPIKey", bundle.Profiles["prod"].APIKey)
+
+	redacted, err := source.Export([]string{"staging"}, true)
+	require.NoError(t, err)
+	assert.Len(t, redacted.Profiles, 1)
+	assert.Equal(t, "", redacted.Profiles["staging"].APIKey)
+	assert.Equal(t, "", redacted.DefaultProfile)
+	assert.True(t, redacted.Redacted)
+
+	_, err = source.Export([]string{"missing"}, false)
+	assert.Error(t, err)
+
+	// Bundles round trip through YAML
+	content, err := yaml.Marshal(bundle)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "bundle.yml")
+	require.NoError(t, ioutil.WriteFile(path, content, 0600))
+
+	bundle, err = ReadBundle(path)
+	require.NoError(t, err)
+
+	target := &Credentials{
+		Profiles: map[string]Profile{
+			"prod": {APIKey: "otherAPIKey", Region: "US", AccountID: 1},
+		},
+		ConfigDirectory: dir,
+	}
+
+	_, err = target.PlanImport(bundle, "merge")
+	assert.Error(t, err)
+
+	plan, err := target.PlanImport(bundle, ConflictSkip)
+	require.NoError(t, err)
+	assert.Equal(t, []ImportChange{
+		{Profile: "prod", Action: ImportSkip, Target: "prod", Changes: []string{"apiKey: changed"}},
+		{Profile: "staging", Action: ImportAdd, Target: "staging"},
+	}, plan)
+
+	plan, err = target.PlanImport(bundle, ConflictRename)
+	require.NoError(t, err)
+	assert.Equal(t, ImportRename, plan[0].Action)
+	assert.Equal(t, "prod-2", plan[0].Target)
+
+	require.NoError(t, target.Import(bundle, plan, ""))
+	assert.Equal(t, "otherAPIKey", target.Profiles["prod"].APIKey)
+	assert.Equal(t, "prodAPIKey", target.Profiles["prod-2"].APIKey)
+	assert.Equal(t, "EU", target.Profiles["staging"].Region)
+	assert.Equal(t, "prod-2", target.DefaultProfile)
+
+	// Importing again changes nothing
+	plan, err = target.PlanImport(bundle, ConflictOverwrite)
+	require.NoError(t, err)
+	assert.Equal(t, ImportOverwrite, plan[0].Action)
+	assert.Equal(t, ImportUnchanged, plan[1].Action)
+}
+
+func TestImportRedactedBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-bundle")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := &Credentials{
+		Profiles: map[string]Profile{
+			"prod": {APIKey: "prodAPIKey", LicenseKey: "prodLicenseKey", Region: "EU", AccountID: 1},
+		},
+	}
+
+	bundle, err := source.Export(nil, true)
+	require.NoError(t, err)
+
+	target := &Credentials{
+		Profiles: map[string]Profile{
+			"prod": {APIKey: "otherAPIKey", LicenseKey: "otherLicenseKey", Region: "US", AccountID: 1},
+		},
+		ConfigDirectory: dir,
+	}
+
+	// The keys left out of the bundle are not reported as changes
+	plan, err := target.PlanImport(bundle, ConflictOverwrite)
+	require.NoError(t, err)
+	assert.Equal(t, []ImportChange{
+		{Profile: "prod", Action: ImportOverwrite, Target: "prod", Changes: []string{"region: US -> EU"}},
+	}, plan)
+
+	// and the overwritten profile keeps its own
+	require.NoError(t, target.Import(bundle, plan, ""))
+	assert.Equal(t, "EU", target.Profiles["prod"].Region)
+	assert.Equal(t, "otherAPIKey", target.Profiles["prod"].APIKey)
+	assert.Equal(t, "otherLicenseKey", target.Profiles["prod"].LicenseKey)
+}
+
+func TestEncryptedBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-bundle")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	os.Setenv(PassphraseEnvVar, "correct horse")
+	defer os.Unsetenv(PassphraseEnvVar)
+
+	bundle := &Bundle{
+		Version:  bundleVersion,
+		Profiles: map[string]BundleProfile{"prod": {APIKey: "prodAPIKey", Region: "us"}},
+	}
+
+	encrypted, err := bundle.Encrypt()
+	require.NoError(t, err)
+
+	content, err := json.Marshal(encrypted)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "prodAPIKey")
+
+	path := filepath.Join(dir, "bundle.json")
+	require.NoError(t, ioutil.WriteFile(path, content, 0600))
+
+	result, err := ReadBundle(path)
+	require.NoError(t, err)
+	assert.Equal(t, bundle, result)
+
+	os.Setenv(PassphraseEnvVar, "wrong")
+	_, err = ReadBundle(path)
+	assert.Error(t, err)
+}
@@ -1,9 +1,14 @@
 package credentials
 
 import (
+	"sort"
+
 	"github.com/jedib0t/go-pretty/v6/text"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
 )
 
 var (
@@ -15,6 +20,18 @@ var (
 	insightsInsertKey string
 	accountID         int
 	licenseKey        string
+	secretStore       string
+	migrateStore      string
+	credentialProcess string
+	proxy             string
+	caBundle          string
+	clientCert        string
+	clientKey         string
+	exportNames       []string
+	exportRedact      bool
+	exportEncrypt     bool
+	importConflict    string
+	importDryRun      bool
 )
 
 // Command is the base command for managing profiles
@@ -34,9 +51,21 @@ var cmdAdd = &cobra.Command{
 The add command creates a new profile for use with the New Relic CLI.
 API key and region are required. An Insights insert key is optional, but required
 for posting custom events with the ` + "`newrelic events`" + `command.
+
+Instead of an API key, a credential process can be given.  The command is run
+through the shell whenever the profile is used, and must print a JSON object
+with any of the apiKey, insightsInsertKey and licenseKey fields to stdout.  An
+optional expiration field (RFC 3339) controls how long the keys are cached.
+
+The proxy, CA bundle and client certificate for mutual TLS can be set for the
+profile, overriding the http settings in the CLI configuration.
 `,
 	Example: "newrelic profile add --name <profileName> --region <region> --apiKey <apiKey> --insightsInsertKey <insightsInsertKey> --accountId <accountId> --licenseKey <licenseKey>",
 	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" && credentialProcess == "" {
+			log.Fatal("one of --apiKey or --credentialProcess is required")
+		}
+
 		WithCredentials(func(creds *Credentials) {
 			p := Profile{
 				Region:            flagRegion,
@@ -44,6 +73,12 @@ for posting custom events with the ` + "`newrelic events`" + `command.
 				InsightsInsertKey: insightsInsertKey,
 				AccountID:         accountID,
 				LicenseKey:        licenseKey,
+				SecretStore:       secretStore,
+				CredentialProcess: credentialProcess,
+				Proxy:             proxy,
+				CABundle:          caBundle,
+				ClientCert:        clientCert,
+				ClientKey:         clientKey,
 			}
 
 			err := creds.AddProfile(profileName, p)
@@ -131,6 +166,115 @@ The delete command removes the profile specified by name.
 	},
 }
 
+var cmdMigrateSecrets = &cobra.Command{
+	Use:   "migrate-secrets",
+	Short: "Move profile keys out of the credentials file",
+	Long: `Move profile keys out of the credentials file
+
+The migrate-secrets command moves the API, Insights insert and license keys of
+a profile out of the plaintext credentials file and into a secret store.  The
+file store encrypts keys with a passphrase, read from the NEW_RELIC_CLI_PASSPHRASE
+environment variable or prompted for in a terminal.  The keyring store uses
+the operating system keyring.  All profiles are migrated when no name is given.
+`,
+	Example: "newrelic profile migrate-secrets --store file --name <profileName>",
+	Run: func(cmd *cobra.Command, args []string) {
+		WithCredentials(func(creds *Credentials) {
+			names := []string{profileName}
+			if profileName == "" {
+				names = []string{}
+				for name := range creds.Profiles {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+			}
+
+			for _, name := range names {
+				err := creds.MigrateSecrets(name, migrateStore)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				log.Infof("moved secrets for profile %s to the %s store", text.FgCyan.Sprint(name), migrateStore)
+			}
+		})
+	},
+}
+
+var cmdExport = &cobra.Command{
+	Use:   "export",
+	Short: "Export profiles to a bundle",
+	Long: `Export profiles to a bundle
+
+The export command prints the named profiles, or all profiles, as a bundle which
+can be loaded elsewhere with the import command.  Use --format YAML for a YAML
+bundle and --output-file to write it to a file.  Keys can be left out with
+--redact, or the whole bundle encrypted with a passphrase using --encrypt.  The
+passphrase is read from NEW_RELIC_CLI_PASSPHRASE or prompted for in a terminal.
+Encrypted bundles are always JSON.
+`,
+	Example: "newrelic profile export --name prod,staging --redact --format YAML --output-file team.yml",
+	Run: func(cmd *cobra.Command, args []string) {
+		WithCredentials(func(creds *Credentials) {
+			bundle, err := creds.Export(exportNames, exportRedact)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if exportEncrypt {
+				encrypted, err := bundle.Encrypt()
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				output.JSON(encrypted)
+				return
+			}
+
+			utils.LogIfFatal(output.Print(bundle))
+		})
+	},
+}
+
+var cmdImport = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import profiles from a bundle",
+	Long: `Import profiles from a bundle
+
+The import command adds the profiles in a JSON or YAML bundle created by the export
+command.  Profiles which already exist with different settings are skipped,
+overwritten or imported under a new name depending on --onConflict.  Use
+--dryRun to see the changes without making them.  Key values are never shown.
+Profiles overwritten from a bundle exported with --redact keep their own keys.
+`,
+	Example: "newrelic profile import team.yml --onConflict rename --dryRun",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		WithCredentials(func(creds *Credentials) {
+			bundle, err := ReadBundle(args[0])
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			plan, err := creds.PlanImport(bundle, importConflict)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			// The global --dry-run only guards HTTP requests, so honour it here too
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			if !importDryRun && !dryRun {
+				if err = creds.Import(bundle, plan, secretStore); err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			utils.LogIfFatal(output.Print(plan))
+		})
+	},
+}
+
 func init() {
 	var err error
 
@@ -142,6 +286,12 @@ func init() {
 	cmdAdd.Flags().StringVarP(&insightsInsertKey, "insightsInsertKey", "", "", "your Insights insert key")
 	cmdAdd.Flags().StringVarP(&licenseKey, "licenseKey", "", "", "your license key")
 	cmdAdd.Flags().IntVarP(&accountID, "accountId", "", 0, "your account ID")
+	cmdAdd.Flags().StringVarP(&credentialProcess, "credentialProcess", "", "", "a command which prints the profile's keys as JSON")
+	cmdAdd.Flags().StringVarP(&secretStore, "secretStore", "", "", "keep keys in a secret store rather than the credentials file: file or keyring")
+	cmdAdd.Flags().StringVarP(&proxy, "proxy", "", "", "the URL of the proxy to use with this profile")
+	cmdAdd.Flags().StringVarP(&caBundle, "caBundle", "", "", "a PEM file of CA certificates to trust with this profile")
+	cmdAdd.Flags().StringVarP(&clientCert, "clientCert", "", "", "a PEM client certificate for mutual TLS")
+	cmdAdd.Flags().StringVarP(&clientKey, "clientKey", "", "", "the PEM private key for --clientCert")
 	err = cmdAdd.MarkFlagRequired("name")
 	if err != nil {
 		log.Error(err)
@@ -152,11 +302,6 @@ func init() {
 		log.Error(err)
 	}
 
-	err = cmdAdd.MarkFlagRequired("apiKey")
-	if err != nil {
-		log.Error(err)
-	}
-
 	// Default
 	Command.AddCommand(cmdDefault)
 	cmdDefault.Flags().StringVarP(&profileName, "name", "n", "", "the profile name to set as default")
@@ -176,4 +321,21 @@ func init() {
 	if err != nil {
 		log.Error(err)
 	}
+
+	// Migrate secrets
+	Command.AddCommand(cmdMigrateSecrets)
+	cmdMigrateSecrets.Flags().StringVarP(&profileName, "name", "n", "", "the profile name to migrate, defaults to all profiles")
+	cmdMigrateSecrets.Flags().StringVarP(&migrateStore, "store", "", SecretStoreFile, "the secret store to move keys to: file or keyring")
+
+	// Export
+	Command.AddCommand(cmdExport)
+	cmdExport.Flags().StringSliceVarP(&exportNames, "name", "n", []string{}, "the profile names to export, defaults to all profiles")
+	cmdExport.Flags().BoolVar(&exportRedact, "redact", false, "leave keys out of the bundle")
+	cmdExport.Flags().BoolVar(&exportEncrypt, "encrypt", false, "encrypt the bundle with a passphrase")
+
+	// Import
+	Command.AddCommand(cmdImport)
+	cmdImport.Flags().StringVar(&importConflict, "onConflict", ConflictSkip, "how to handle profiles which already exist: skip, overwrite or rename")
+	cmdImport.Flags().BoolVar(&importDryRun, "dryRun", false, "show the changes without making them")
+	cmdImport.Flags().StringVar(&secretStore, "secretStore", "", "keep imported keys in a secret store: file or keyring")
 }
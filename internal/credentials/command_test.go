@@ -49,3 +49,27 @@ func TestCredentialsDelete(t *testing.T) {
 	testcobra.CheckCobraRequiredFlags(t, cmdDelete, []string{"name"})
 	testcobra.CheckCobraCommandAliases(t, cmdDelete, []string{"remove", "rm"}) // DEPRECATED: from nr1 cli
 }
+
+func TestCredentialsMigrateSecrets(t *testing.T) {
+	assert.Equal(t, "migrate-secrets", cmdMigrateSecrets.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdMigrateSecrets)
+	testcobra.CheckCobraRequiredFlags(t, cmdMigrateSecrets, []string{})
+	testcobra.CheckCobraCommandAliases(t, cmdMigrateSecrets, []string{})
+}
+
+func TestCredentialsExport(t *testing.T) {
+	assert.Equal(t, "export", cmdExport.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdExport)
+	testcobra.CheckCobraRequiredFlags(t, cmdExport, []string{})
+	testcobra.CheckCobraCommandAliases(t, cmdExport, []string{})
+}
+
+func TestCredentialsImport(t *testing.T) {
+	assert.Equal(t, "import", cmdImport.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdImport)
+	testcobra.CheckCobraRequiredFlags(t, cmdImport, []string{})
+	testcobra.CheckCobraCommandAliases(t, cmdImport, []string{})
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/newrelic/newrelic-cli/internal/config"
 	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
 )
 
 const (
@@ -21,6 +22,7 @@ const (
 	defaultConfigType    = "json"
 	defaultProfileString = " (default)"
 	hiddenKeyString      = "<hidden>"
+	processKeyString     = "<process>"
 )
 
 // Credentials is the metadata around all configured profiles
@@ -72,32 +74,46 @@ func (c *Credentials) profileExists(profileName string) bool {
 
 // AddProfile adds a new profile to the credentials file.
 func (c *Credentials) AddProfile(profileName string, p Profile) error {
-	var err error
-
 	if c.profileExists(profileName) {
 		return fmt.Errorf("profile with name %s already exists", profileName)
 	}
 
-	// Case fold the region
-	p.Region = strings.ToUpper(p.Region)
+	if err := c.setProfile(profileName, p); err != nil {
+		return err
+	}
 
-	c.Profiles[profileName] = p
+	return c.writeProfiles()
+}
 
-	file, _ := json.MarshalIndent(c.Profiles, "", "  ")
-	defaultCredentialsFile := os.ExpandEnv(fmt.Sprintf("%s/%s.json", c.ConfigDirectory, DefaultCredentialsFile))
+// setProfile adds or replaces a profile, moving its keys into its secret
+// store when it has one.  The credentials file is not written.
+func (c *Credentials) setProfile(profileName string, p Profile) error {
+	// Case fold the region
+	p.Region = strings.ToUpper(p.Region)
 
-	if _, err = os.Stat(c.ConfigDirectory); os.IsNotExist(err) {
-		err = os.MkdirAll(c.ConfigDirectory, os.ModePerm)
+	// Keep the keys out of the credentials file when using a secret store
+	if p.SecretStore != "" {
+		store, err := NewSecretStore(p.SecretStore, c.ConfigDirectory)
 		if err != nil {
 			return err
 		}
+
+		if err = store.Set(profileName, p.secrets()); err != nil {
+			return fmt.Errorf("error writing secrets for profile %s to %s store: %s", profileName, p.SecretStore, err)
+		}
+
+		p = p.withSecrets(Secrets{})
 	}
 
-	err = ioutil.WriteFile(defaultCredentialsFile, file, 0600)
-	if err != nil {
-		return err
+	// Don't leave secrets behind in a store the profile no longer uses
+	if previous, ok := c.Profiles[profileName]; ok && previous.SecretStore != "" && previous.SecretStore != p.SecretStore {
+		if store, err := NewSecretStore(previous.SecretStore, c.ConfigDirectory); err == nil {
+			utils.LogIfError(store.Delete(profileName))
+		}
 	}
 
+	c.Profiles[profileName] = p
+
 	return nil
 }
 
@@ -107,16 +123,26 @@ func (c *Credentials) RemoveProfile(profileName string) error {
 		return fmt.Errorf("profile with name %s not found", profileName)
 	}
 
-	delete(c.Profiles, profileName)
+	storeName := c.Profiles[profileName].SecretStore
 
-	file, _ := json.MarshalIndent(c.Profiles, "", "  ")
-	defaultCredentialsFile := os.ExpandEnv(fmt.Sprintf("%s/%s.json", c.ConfigDirectory, DefaultCredentialsFile))
+	delete(c.Profiles, profileName)
 
-	err := ioutil.WriteFile(defaultCredentialsFile, file, 0600)
+	err := c.writeProfiles()
 	if err != nil {
 		return err
 	}
 
+	if storeName != "" {
+		store, err := NewSecretStore(storeName, c.ConfigDirectory)
+		if err != nil {
+			return err
+		}
+
+		if err = store.Delete(profileName); err != nil {
+			log.Warnf("unable to remove secrets for profile %s: %s", profileName, err)
+		}
+	}
+
 	if profileName == c.DefaultProfile {
 		c.DefaultProfile = ""
 		defaultProfileFileName := os.ExpandEnv(fmt.Sprintf("%s/%s.json", c.ConfigDirectory, DefaultProfileFile))
@@ -132,6 +158,25 @@ func (c *Credentials) RemoveProfile(profileName string) error {
 	return nil
 }
 
+// writeProfiles saves the profiles to the credentials file.
+func (c *Credentials) writeProfiles() error {
+	file, err := json.MarshalIndent(c.Profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err = os.Stat(c.ConfigDirectory); os.IsNotExist(err) {
+		err = os.MkdirAll(c.ConfigDirectory, os.ModePerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	defaultCredentialsFile := os.ExpandEnv(fmt.Sprintf("%s/%s.json", c.ConfigDirectory, DefaultCredentialsFile))
+
+	return ioutil.WriteFile(defaultCredentialsFile, file, 0600)
+}
+
 // SetDefaultProfile modifies the profile name to use by default.
 func (c *Credentials) SetDefaultProfile(profileName string) error {
 	if !c.profileExists(profileName) {
@@ -187,6 +232,24 @@ func (c *Credentials) List() {
 			licenseKey = text.FgHiBlack.Sprint(hiddenKeyString)
 		}
 
+		if v.SecretStore != "" || v.CredentialProcess != "" {
+			storedKeyString := text.FgHiBlack.Sprintf("<%s>", v.SecretStore)
+			if v.CredentialProcess != "" {
+				storedKeyString = text.FgHiBlack.Sprint(processKeyString)
+			}
+
+			apiKey, insightsInsertKey, licenseKey = storedKeyString, storedKeyString, storedKeyString
+
+			if showKeys {
+				resolved, err := c.resolveSecrets(k, v)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				v = resolved
+			}
+		}
+
 		if showKeys {
 			apiKey = v.APIKey
 			insightsInsertKey = v.InsightsInsertKey
@@ -6,7 +6,14 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/config"
 )
 
-var defaultProfile *Profile
+var (
+	defaultProfile  *Profile
+	profileOverride string
+
+	// Set from the CLI configuration, see SetConfigOverrides
+	configProfile   string
+	configAccountID int
+)
 
 // WithCredentials loads and returns the CLI credentials.
 func WithCredentials(f func(c *Credentials)) {
@@ -34,6 +41,23 @@ func DefaultProfile() *Profile {
 	return defaultProfile
 }
 
+// SetProfileOverride selects the profile to use for this invocation without
+// changing the stored default profile.
+func SetProfileOverride(name string) {
+	profileOverride = name
+	defaultProfile = nil
+}
+
+// SetConfigOverrides applies the profile and account ID from the CLI
+// configuration.  The profile is used when none is selected with --profile
+// or NEW_RELIC_PROFILE, and the account ID replaces the profile's own unless
+// the profile was chosen that way.
+func SetConfigOverrides(profileName string, accountID int) {
+	configProfile = profileName
+	configAccountID = accountID
+	defaultProfile = nil
+}
+
 // SetDefaultProfile allows mocking of the default profile for testing purposes.
 func SetDefaultProfile(p Profile) {
 	defaultProfile = &p
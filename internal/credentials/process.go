@@ -0,0 +1,113 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// credentialProcessTimeout limits how long a credential process may run
+	credentialProcessTimeout = 30 * time.Second
+
+	// credentialProcessTTL is how long credentials without an expiration
+	// are cached for
+	credentialProcessTTL = 15 * time.Minute
+
+	credentialProcessCache   = map[string]cachedCredentials{}
+	credentialProcessCacheMu sync.Mutex
+)
+
+// credentialProcessOutput is the JSON document a credential process writes
+// to stdout.  The expiration is optional and formatted as RFC 3339.
+type credentialProcessOutput struct {
+	Secrets
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
+
+type cachedCredentials struct {
+	secrets Secrets
+	expires time.Time
+}
+
+// runCredentialProcess executes the command through the shell and parses
+// the keys it prints, reusing earlier results until they expire.
+func runCredentialProcess(command string) (*Secrets, error) {
+	credentialProcessCacheMu.Lock()
+	defer credentialProcessCacheMu.Unlock()
+
+	if cached, ok := credentialProcessCache[command]; ok && time.Now().Before(cached.expires) {
+		return &cached.secrets, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), credentialProcessTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	log.Debugf("running credential process: %s", command)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential process failed: %s %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var result credentialProcessOutput
+	if err = json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("error parsing credential process output: %s", err)
+	}
+
+	if result.Secrets == (Secrets{}) {
+		return nil, errors.New("credential process did not return any keys")
+	}
+
+	expires := time.Now().Add(credentialProcessTTL)
+	if result.Expiration != nil {
+		if !time.Now().Before(*result.Expiration) {
+			return nil, fmt.Errorf("credential process returned credentials which expired at %s", result.Expiration.Format(time.RFC3339))
+		}
+
+		expires = *result.Expiration
+	}
+
+	credentialProcessCache[command] = cachedCredentials{
+		secrets: result.Secrets,
+		expires: expires,
+	}
+
+	return &result.Secrets, nil
+}
+
+// mergeSecrets returns the secrets with any keys set in override applied
+func mergeSecrets(s Secrets, override Secrets) Secrets {
+	if override.APIKey != "" {
+		s.APIKey = override.APIKey
+	}
+
+	if override.InsightsInsertKey != "" {
+		s.InsightsInsertKey = override.InsightsInsertKey
+	}
+
+	if override.LicenseKey != "" {
+		s.LicenseKey = override.LicenseKey
+	}
+
+	return s
+}
@@ -0,0 +1,61 @@
+// +build unit
+
+package credentials
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential process tests use a POSIX shell")
+	}
+
+	dir, err := ioutil.TempDir("", "newrelic-cli-process")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Each run of the process is recorded so we can check the cache
+	calls := filepath.Join(dir, "calls")
+	command := fmt.Sprintf(`echo run >> %s; echo '{"apiKey": "processAPIKey"}'`, calls)
+
+	c := &Credentials{
+		DefaultProfile: "default",
+		Profiles: map[string]Profile{
+			"default": {APIKey: "fileAPIKey", LicenseKey: "fileLicenseKey", AccountID: 1, CredentialProcess: command},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		p, err := c.ActiveProfile()
+		require.NoError(t, err)
+		assert.Equal(t, "processAPIKey", p.APIKey)
+		assert.Equal(t, "fileLicenseKey", p.LicenseKey)
+		assert.Equal(t, 1, p.AccountID)
+	}
+
+	content, err := ioutil.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(content), "run"))
+
+	// Expired credentials are rejected
+	expired := time.Now().Add(-time.Minute).Format(time.RFC3339)
+	_, err = runCredentialProcess(fmt.Sprintf(`echo '{"apiKey": "x", "expiration": "%s"}'`, expired))
+	assert.Error(t, err)
+
+	_, err = runCredentialProcess("echo '{}'")
+	assert.Error(t, err)
+
+	_, err = runCredentialProcess("exit 1")
+	assert.Error(t, err)
+}
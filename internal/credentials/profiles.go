@@ -28,6 +28,12 @@ type Profile struct {
 	Region            string `mapstructure:"region" json:"region,omitempty"`                       // Region to use for New Relic resources
 	AccountID         int    `mapstructure:"accountID" json:"accountID,omitempty"`                 // AccountID to use for New Relic resources
 	LicenseKey        string `mapstructure:"licenseKey" json:"licenseKey,omitempty"`               // License key to use for agent config and ingest
+	SecretStore       string `mapstructure:"secretStore" json:"secretStore,omitempty"`             // Secret store holding the keys, empty when stored inline
+	CredentialProcess string `mapstructure:"credentialProcess" json:"credentialProcess,omitempty"` // Command printing the keys as JSON, see runCredentialProcess
+	Proxy             string `mapstructure:"proxy" json:"proxy,omitempty"`                         // Proxy URL, overriding http.proxy in the config
+	CABundle          string `mapstructure:"caBundle" json:"caBundle,omitempty"`                   // CA bundle path, overriding http.caBundle in the config
+	ClientCert        string `mapstructure:"clientCert" json:"clientCert,omitempty"`               // Client certificate path for mutual TLS
+	ClientKey         string `mapstructure:"clientKey" json:"clientKey,omitempty"`                 // Client key path for mutual TLS
 }
 
 // LoadProfiles reads the credential profiles from the default path.
@@ -56,17 +62,94 @@ func LoadDefaultProfile(configDir string) (string, error) {
 	return defProfile, nil
 }
 
-// Default returns the default profile
+// Default returns the profile in use for this invocation, with any
+// environment overrides applied.  See ActiveProfileName.  A profile which
+// can't be loaded is fatal when selected with --profile or NEW_RELIC_PROFILE,
+// otherwise a warning.
 func (c *Credentials) Default() *Profile {
+	p, err := c.ActiveProfile()
+	if err != nil {
+		if profileSelected() {
+			log.Fatal(err)
+		}
+
+		log.Warn(err)
+	}
+
+	return p
+}
+
+// ActiveProfileName returns the name of the profile in use for this
+// invocation: the profile selected with --profile, then the one named by
+// the NEW_RELIC_PROFILE environment variable, then the one set in the CLI
+// configuration, then the default profile.
+func (c *Credentials) ActiveProfileName() string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+
+	if envProfile := os.Getenv("NEW_RELIC_PROFILE"); envProfile != "" {
+		return envProfile
+	}
+
+	if configProfile != "" {
+		return configProfile
+	}
+
+	return c.DefaultProfile
+}
+
+// profileSelected returns true if a profile was chosen for this invocation
+// with --profile or NEW_RELIC_PROFILE.
+func profileSelected() bool {
+	return profileOverride != "" || os.Getenv("NEW_RELIC_PROFILE") != ""
+}
+
+// ActiveProfile returns the profile in use for this invocation, with any
+// environment overrides applied.  An error is returned if a profile was
+// explicitly selected but does not exist.  Keys are taken from the
+// environment first, then the profile's credential process, then its
+// secret store or the credentials file.
+func (c *Credentials) ActiveProfile() (*Profile, error) {
 	var p *Profile
-	if c.DefaultProfile != "" {
-		if val, ok := c.Profiles[c.DefaultProfile]; ok {
+
+	name := c.ActiveProfileName()
+	if name != "" {
+		if val, ok := c.Profiles[name]; ok {
+			val, err := c.resolveSecrets(name, val)
+			if err != nil {
+				return applyOverrides(&val), err
+			}
+
+			// A profile chosen for this invocation keeps its own account
+			if configAccountID != 0 && !profileSelected() {
+				val.AccountID = configAccountID
+			}
+
 			p = &val
+		} else if name != c.DefaultProfile {
+			return applyOverrides(nil), fmt.Errorf("profile with name %s not found", name)
 		}
 	}
 
 	p = applyOverrides(p)
-	return p
+	return p, nil
+}
+
+// GetProfile returns the named profile with its keys loaded from any
+// secret store or credential process, ignoring environment overrides.
+func (c *Credentials) GetProfile(name string) (*Profile, error) {
+	val, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile with name %s not found", name)
+	}
+
+	val, err := c.resolveSecrets(name, val)
+	if err != nil {
+		return nil, err
+	}
+
+	return &val, nil
 }
 
 // applyOverrides reads Profile info out of the Environment to override config
@@ -192,12 +275,24 @@ func (p Profile) MarshalJSON() ([]byte, error) {
 		Region            string `json:"region,omitempty"`
 		AccountID         int    `json:"accountID,omitempty"`
 		LicenseKey        string `json:"licenseKey,omitempty"`
+		SecretStore       string `json:"secretStore,omitempty"`
+		CredentialProcess string `json:"credentialProcess,omitempty"`
+		Proxy             string `json:"proxy,omitempty"`
+		CABundle          string `json:"caBundle,omitempty"`
+		ClientCert        string `json:"clientCert,omitempty"`
+		ClientKey         string `json:"clientKey,omitempty"`
 	}{
 		APIKey:            p.APIKey,
 		InsightsInsertKey: p.InsightsInsertKey,
 		AccountID:         p.AccountID,
 		LicenseKey:        p.LicenseKey,
 		Region:            strings.ToLower(p.Region),
+		SecretStore:       p.SecretStore,
+		CredentialProcess: p.CredentialProcess,
+		Proxy:             p.Proxy,
+		CABundle:          p.CABundle,
+		ClientCert:        p.ClientCert,
+		ClientKey:         p.ClientKey,
 	})
 }
 
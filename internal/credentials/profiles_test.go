@@ -3,9 +3,13 @@
 package credentials
 
 import (
+	"os"
 	"testing"
 
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProfileMarshal(t *testing.T) {
@@ -21,3 +25,124 @@ func TestProfileMarshal(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, `{"apiKey":"testAPIKey","region":"test"}`, string(m))
 }
+
+func TestActiveProfile(t *testing.T) {
+	// Do not run this in parallel, we are messing with the environment
+	if val, ok := os.LookupEnv("NEW_RELIC_PROFILE"); ok {
+		defer os.Setenv("NEW_RELIC_PROFILE", val)
+	} else {
+		defer os.Unsetenv("NEW_RELIC_PROFILE")
+	}
+	defer SetProfileOverride("")
+
+	c := &Credentials{
+		DefaultProfile: "default",
+		Profiles: map[string]Profile{
+			"default": {APIKey: "defaultAPIKey"},
+			"env":     {APIKey: "envAPIKey"},
+			"flag":    {APIKey: "flagAPIKey"},
+		},
+	}
+
+	os.Unsetenv("NEW_RELIC_PROFILE")
+	assert.Equal(t, "default", c.ActiveProfileName())
+
+	os.Setenv("NEW_RELIC_PROFILE", "env")
+	assert.Equal(t, "env", c.ActiveProfileName())
+
+	SetProfileOverride("flag")
+	assert.Equal(t, "flag", c.ActiveProfileName())
+
+	p, err := c.ActiveProfile()
+	assert.NoError(t, err)
+	assert.Equal(t, "flagAPIKey", p.APIKey)
+
+	// The stored default is left untouched
+	assert.Equal(t, "default", c.DefaultProfile)
+
+	SetProfileOverride("missing")
+	_, err = c.ActiveProfile()
+	assert.Error(t, err)
+}
+
+func TestActiveProfileConfigAccountID(t *testing.T) {
+	// Do not run this in parallel, we are messing with the environment
+	if val, ok := os.LookupEnv("NEW_RELIC_PROFILE"); ok {
+		defer os.Setenv("NEW_RELIC_PROFILE", val)
+	} else {
+		defer os.Unsetenv("NEW_RELIC_PROFILE")
+	}
+	defer SetProfileOverride("")
+	defer SetConfigOverrides("", 0)
+
+	c := &Credentials{
+		DefaultProfile: "default",
+		Profiles: map[string]Profile{
+			"default": {APIKey: "defaultAPIKey", AccountID: 1},
+			"other":   {APIKey: "otherAPIKey", AccountID: 2},
+		},
+	}
+
+	os.Unsetenv("NEW_RELIC_PROFILE")
+	SetConfigOverrides("", 3)
+
+	p, err := c.ActiveProfile()
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.AccountID)
+
+	// A profile selected for the invocation keeps its own account ID
+	SetProfileOverride("other")
+	p, err = c.ActiveProfile()
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.AccountID)
+
+	SetProfileOverride("")
+	os.Setenv("NEW_RELIC_PROFILE", "other")
+	p, err = c.ActiveProfile()
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.AccountID)
+}
+
+func TestDefaultProfileErrors(t *testing.T) {
+	if val, ok := os.LookupEnv("NEW_RELIC_PROFILE"); ok {
+		defer os.Setenv("NEW_RELIC_PROFILE", val)
+	} else {
+		defer os.Unsetenv("NEW_RELIC_PROFILE")
+	}
+	defer SetProfileOverride("")
+	os.Unsetenv("NEW_RELIC_PROFILE")
+
+	hook := test.NewGlobal()
+	defer hook.Reset()
+
+	// Stop at a fatal error instead of exiting
+	log.StandardLogger().ExitFunc = func(code int) { panic(code) }
+	defer func() { log.StandardLogger().ExitFunc = nil }()
+
+	c := &Credentials{
+		Profiles: map[string]Profile{
+			"broken": {CredentialProcess: "false"},
+		},
+		DefaultProfile: "broken",
+	}
+
+	fatal := func() (exited bool) {
+		defer func() { exited = recover() != nil }()
+		c.Default()
+		return
+	}
+
+	// A default profile which can't be loaded is warned about
+	assert.False(t, fatal())
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, log.WarnLevel, hook.LastEntry().Level)
+
+	// but one chosen for this invocation is fatal
+	SetProfileOverride("missing")
+	assert.True(t, fatal())
+	assert.Equal(t, log.FatalLevel, hook.LastEntry().Level)
+
+	SetProfileOverride("")
+	os.Setenv("NEW_RELIC_PROFILE", "broken")
+	assert.True(t, fatal())
+}
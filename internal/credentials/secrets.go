@@ -0,0 +1,144 @@
+package credentials
+
+import (
+	"fmt"
+)
+
+const (
+	// SecretStoreFile keeps profile secrets in a passphrase encrypted file
+	// within the configuration directory.
+	SecretStoreFile = "file"
+
+	// SecretStoreKeyring keeps profile secrets in the operating system keyring.
+	SecretStoreKeyring = "keyring"
+)
+
+// Secrets contains the sensitive fields of a single profile
+type Secrets struct {
+	APIKey            string `json:"apiKey,omitempty"`
+	InsightsInsertKey string `json:"insightsInsertKey,omitempty"`
+	LicenseKey        string `json:"licenseKey,omitempty"`
+}
+
+// SecretStore persists profile secrets outside of the credentials file
+type SecretStore interface {
+	Get(profileName string) (*Secrets, error)
+	Set(profileName string, s Secrets) error
+	Delete(profileName string) error
+}
+
+// NewSecretStore returns the secret store with the given name, using
+// configDir for any files it needs.
+func NewSecretStore(name string, configDir string) (SecretStore, error) {
+	switch name {
+	case SecretStoreFile:
+		return newFileSecretStore(configDir), nil
+	case SecretStoreKeyring:
+		return newKeyringSecretStore()
+	}
+
+	return nil, fmt.Errorf("unknown secret store %q, valid values are %s and %s", name, SecretStoreFile, SecretStoreKeyring)
+}
+
+// secrets returns the sensitive fields of the profile
+func (p Profile) secrets() Secrets {
+	return Secrets{
+		APIKey:            p.APIKey,
+		InsightsInsertKey: p.InsightsInsertKey,
+		LicenseKey:        p.LicenseKey,
+	}
+}
+
+// withSecrets returns a copy of the profile with the given secrets applied
+func (p Profile) withSecrets(s Secrets) Profile {
+	p.APIKey = s.APIKey
+	p.InsightsInsertKey = s.InsightsInsertKey
+	p.LicenseKey = s.LicenseKey
+
+	return p
+}
+
+// loadStoredSecrets loads the secrets for a profile kept in a secret store
+func (c *Credentials) loadStoredSecrets(profileName string, p Profile) (Profile, error) {
+	if p.SecretStore == "" {
+		return p, nil
+	}
+
+	store, err := NewSecretStore(p.SecretStore, c.ConfigDirectory)
+	if err != nil {
+		return p, err
+	}
+
+	s, err := store.Get(profileName)
+	if err != nil {
+		return p, fmt.Errorf("error reading secrets for profile %s from %s store: %s", profileName, p.SecretStore, err)
+	}
+
+	return p.withSecrets(*s), nil
+}
+
+// resolveSecrets loads the secrets for a profile kept in a secret store,
+// then applies any keys returned by its credential process.
+func (c *Credentials) resolveSecrets(profileName string, p Profile) (Profile, error) {
+	p, err := c.loadStoredSecrets(profileName, p)
+	if err != nil {
+		return p, err
+	}
+
+	if p.CredentialProcess != "" {
+		s, err := runCredentialProcess(p.CredentialProcess)
+		if err != nil {
+			return p, fmt.Errorf("error reading secrets for profile %s: %s", profileName, err)
+		}
+
+		p = p.withSecrets(mergeSecrets(p.secrets(), *s))
+	}
+
+	return p, nil
+}
+
+// MigrateSecrets moves the keys of the named profile out of the
+// credentials file and into the given secret store.
+func (c *Credentials) MigrateSecrets(profileName string, storeName string) error {
+	p, ok := c.Profiles[profileName]
+	if !ok {
+		return fmt.Errorf("profile with name %s not found", profileName)
+	}
+
+	if p.SecretStore == storeName {
+		return nil
+	}
+
+	// Secrets may already live in another store
+	p, err := c.loadStoredSecrets(profileName, p)
+	if err != nil {
+		return err
+	}
+
+	store, err := NewSecretStore(storeName, c.ConfigDirectory)
+	if err != nil {
+		return err
+	}
+
+	if err = store.Set(profileName, p.secrets()); err != nil {
+		return fmt.Errorf("error writing secrets for profile %s to %s store: %s", profileName, storeName, err)
+	}
+
+	previousStore := c.Profiles[profileName].SecretStore
+
+	p = p.withSecrets(Secrets{})
+	p.SecretStore = storeName
+	c.Profiles[profileName] = p
+
+	if err = c.writeProfiles(); err != nil {
+		return err
+	}
+
+	if previousStore != "" {
+		if old, err := NewSecretStore(previousStore, c.ConfigDirectory); err == nil {
+			return old.Delete(profileName)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,348 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+const (
+	// DefaultSecretsFile is the file holding encrypted profile secrets
+	DefaultSecretsFile = "secrets"
+
+	// PassphraseEnvVar is the environment variable holding the passphrase
+	// for the encrypted secrets file, used when no terminal is available.
+	PassphraseEnvVar = "NEW_RELIC_CLI_PASSPHRASE"
+
+	secretsFileVersion = 1
+
+	// scrypt parameters recommended for interactive logins
+	scryptN      = 32768
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 16
+)
+
+var (
+	// The passphrases entered for each secrets file, so they are only asked
+	// for once however many profiles are read
+	filePassphrasesMutex sync.Mutex
+	filePassphrases      = map[string][]byte{}
+
+	// promptPassphrase reads a passphrase from the terminal
+	promptPassphrase = readTerminalPassphrase
+)
+
+// encryptedData is the on-disk format of the secrets file and of
+// encrypted profile bundles
+type encryptedData struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// fileSecretStore keeps the secrets of all profiles in a single file,
+// encrypted with AES-GCM using a key derived from a passphrase.
+type fileSecretStore struct {
+	path string
+}
+
+func newFileSecretStore(configDir string) *fileSecretStore {
+	return &fileSecretStore{
+		path: os.ExpandEnv(fmt.Sprintf("%s/%s.json", configDir, DefaultSecretsFile)),
+	}
+}
+
+func (s *fileSecretStore) Get(profileName string) (*Secrets, error) {
+	all, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, ok := all[profileName]
+	if !ok {
+		return nil, fmt.Errorf("no secrets found for profile %s", profileName)
+	}
+
+	return &secrets, nil
+}
+
+func (s *fileSecretStore) Set(profileName string, secrets Secrets) error {
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	all[profileName] = secrets
+
+	return s.write(all)
+}
+
+func (s *fileSecretStore) Delete(profileName string) error {
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := all[profileName]; !ok {
+		return nil
+	}
+
+	delete(all, profileName)
+
+	return s.write(all)
+}
+
+// read decrypts the secrets file, returning an empty set if it does not exist
+func (s *fileSecretStore) read() (map[string]Secrets, error) {
+	all := map[string]Secrets{}
+
+	content, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var enc encryptedData
+	if err = json.Unmarshal(content, &enc); err != nil {
+		return nil, fmt.Errorf("error parsing secrets file %s: %s", s.path, err)
+	}
+
+	passphrase, err := s.getPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := openData(&enc, passphrase)
+	if err != nil {
+		s.forgetPassphrase()
+		return nil, err
+	}
+
+	if err = json.Unmarshal(plain, &all); err != nil {
+		return nil, fmt.Errorf("error parsing secrets file %s: %s", s.path, err)
+	}
+
+	return all, nil
+}
+
+// write encrypts the secrets with a fresh salt and nonce, replacing the
+// secrets file atomically.
+func (s *fileSecretStore) write(all map[string]Secrets) error {
+	plain, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+
+	_, statErr := os.Stat(s.path)
+
+	passphrase, err := s.getPassphrase(os.IsNotExist(statErr))
+	if err != nil {
+		return err
+	}
+
+	enc, err := sealData(plain, passphrase)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(enc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // nolint:errcheck
+
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close() // nolint:errcheck
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// getPassphrase returns the passphrase for the secrets file, asking for it
+// the first time it's needed.  A passphrase for a new file is asked for
+// twice, since a mistake would lock away every key kept in it.
+func (s *fileSecretStore) getPassphrase(create bool) ([]byte, error) {
+	if env := os.Getenv(PassphraseEnvVar); env != "" {
+		return []byte(env), nil
+	}
+
+	filePassphrasesMutex.Lock()
+	defer filePassphrasesMutex.Unlock()
+
+	if passphrase, ok := filePassphrases[s.path]; ok {
+		return passphrase, nil
+	}
+
+	read := readPassphrase
+	if create {
+		read = readNewPassphrase
+	}
+
+	passphrase, err := read("Secrets file passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+
+	filePassphrases[s.path] = passphrase
+
+	return passphrase, nil
+}
+
+// forgetPassphrase drops a passphrase which failed to open the secrets
+// file, so it's asked for again
+func (s *fileSecretStore) forgetPassphrase() {
+	filePassphrasesMutex.Lock()
+	delete(filePassphrases, s.path)
+	filePassphrasesMutex.Unlock()
+}
+
+// sealData encrypts data with a key derived from the passphrase, using a
+// fresh salt and nonce.
+func sealData(plain []byte, passphrase []byte) (*encryptedData, error) {
+	enc := &encryptedData{
+		Version: secretsFileVersion,
+		Salt:    make([]byte, saltLen),
+	}
+
+	if _, err := rand.Read(enc.Salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newCipher(passphrase, enc.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	enc.Nonce = make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(enc.Nonce); err != nil {
+		return nil, err
+	}
+
+	enc.Data = gcm.Seal(nil, enc.Nonce, plain, nil)
+
+	return enc, nil
+}
+
+// openData decrypts data sealed with sealData
+func openData(enc *encryptedData, passphrase []byte) ([]byte, error) {
+	if enc.Version != secretsFileVersion {
+		return nil, fmt.Errorf("unsupported encryption version %d", enc.Version)
+	}
+
+	gcm, err := newCipher(passphrase, enc.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := gcm.Open(nil, enc.Nonce, enc.Data, nil)
+	if err != nil {
+		return nil, errors.New("unable to decrypt, check your passphrase")
+	}
+
+	return plain, nil
+}
+
+// newCipher derives the encryption key for the given salt from the passphrase
+func newCipher(passphrase []byte, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// readPassphrase reads a passphrase from the environment, falling back
+// to prompting for it when running in a terminal.
+func readPassphrase(prompt string) ([]byte, error) {
+	if env := os.Getenv(PassphraseEnvVar); env != "" {
+		return []byte(env), nil
+	}
+
+	return promptPassphrase(prompt)
+}
+
+// readNewPassphrase reads a passphrase like readPassphrase, asking for it
+// a second time to confirm it when prompting.
+func readNewPassphrase(prompt string) ([]byte, error) {
+	if env := os.Getenv(PassphraseEnvVar); env != "" {
+		return []byte(env), nil
+	}
+
+	passphrase, err := promptPassphrase(prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmed, err := promptPassphrase("Confirm passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+
+	if string(passphrase) != string(confirmed) {
+		return nil, errors.New("the passphrases don't match")
+	}
+
+	return passphrase, nil
+}
+
+// readTerminalPassphrase prompts for a passphrase in a terminal
+func readTerminalPassphrase(prompt string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("a passphrase is required, set %s", PassphraseEnvVar)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+
+	return passphrase, nil
+}
@@ -0,0 +1,114 @@
+package credentials
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// keyringService is the service name secrets are stored under
+const keyringService = "newrelic-cli"
+
+// keyringSecretStore keeps profile secrets in the operating system keyring
+// using the platform's command line tooling: security(1) on macOS and
+// secret-tool(1) from libsecret on Linux.
+type keyringSecretStore struct {
+	tool string
+}
+
+func newKeyringSecretStore() (*keyringSecretStore, error) {
+	var tool string
+
+	switch runtime.GOOS {
+	case "darwin":
+		tool = "security"
+	case "linux":
+		tool = "secret-tool"
+	default:
+		return nil, fmt.Errorf("the keyring secret store is not supported on %s, use the %s store instead", runtime.GOOS, SecretStoreFile)
+	}
+
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("the keyring secret store requires %s: %s", tool, err)
+	}
+
+	return &keyringSecretStore{tool: tool}, nil
+}
+
+func (s *keyringSecretStore) Get(profileName string) (*Secrets, error) {
+	var args []string
+
+	if s.tool == "security" {
+		args = []string{"find-generic-password", "-s", keyringService, "-a", profileName, "-w"}
+	} else {
+		args = []string{"lookup", "service", keyringService, "profile", profileName}
+	}
+
+	out, err := s.run(nil, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets Secrets
+	if err = json.Unmarshal(bytes.TrimSpace(out), &secrets); err != nil {
+		return nil, fmt.Errorf("error parsing keyring entry: %s", err)
+	}
+
+	return &secrets, nil
+}
+
+func (s *keyringSecretStore) Set(profileName string, secrets Secrets) error {
+	value, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	// Neither tool may see the secret in its arguments, where any local user
+	// could read it from the process list.  security(1) reads the command from
+	// stdin in interactive mode, with the value hex encoded so it needs no quoting.
+	if s.tool == "security" {
+		command := fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n", keyringService, strconv.Quote(profileName), hex.EncodeToString(value))
+		_, err = s.run([]byte(command), "-i")
+		return err
+	}
+
+	label := fmt.Sprintf("New Relic CLI profile %s", profileName)
+	_, err = s.run(value, "store", "--label", label, "service", keyringService, "profile", profileName)
+
+	return err
+}
+
+func (s *keyringSecretStore) Delete(profileName string) error {
+	var err error
+
+	if s.tool == "security" {
+		_, err = s.run(nil, "delete-generic-password", "-s", keyringService, "-a", profileName)
+	} else {
+		_, err = s.run(nil, "clear", "service", keyringService, "profile", profileName)
+	}
+
+	return err
+}
+
+func (s *keyringSecretStore) run(stdin []byte, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(s.tool, args...)
+	cmd.Stderr = &stderr
+
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %s %s", s.tool, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
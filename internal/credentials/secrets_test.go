@@ -0,0 +1,144 @@
+// +build unit
+
+package credentials
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSecretStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	os.Setenv(PassphraseEnvVar, "correct horse")
+	defer os.Unsetenv(PassphraseEnvVar)
+
+	store, err := NewSecretStore(SecretStoreFile, dir)
+	require.NoError(t, err)
+
+	_, err = store.Get("testProfile")
+	assert.Error(t, err)
+
+	secrets := Secrets{APIKey: "NRAK-123", LicenseKey: "license"}
+	require.NoError(t, store.Set("testProfile", secrets))
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, DefaultSecretsFile+".json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "NRAK-123")
+
+	info, err := os.Stat(filepath.Join(dir, DefaultSecretsFile+".json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// A new store reads what was written with the same passphrase
+	store, err = NewSecretStore(SecretStoreFile, dir)
+	require.NoError(t, err)
+
+	result, err := store.Get("testProfile")
+	require.NoError(t, err)
+	assert.Equal(t, secrets, *result)
+
+	// But not with a different one
+	os.Setenv(PassphraseEnvVar, "wrong")
+	store, err = NewSecretStore(SecretStoreFile, dir)
+	require.NoError(t, err)
+
+	_, err = store.Get("testProfile")
+	assert.Error(t, err)
+
+	os.Setenv(PassphraseEnvVar, "correct horse")
+	store, err = NewSecretStore(SecretStoreFile, dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Delete("testProfile"))
+	_, err = store.Get("testProfile")
+	assert.Error(t, err)
+}
+
+func TestFileSecretStorePassphrase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var prompts []string
+	entered := []string{"correct horse", "correct horse"}
+
+	defer func(p func(string) ([]byte, error)) { promptPassphrase = p }(promptPassphrase)
+	defer func() { filePassphrases = map[string][]byte{} }()
+	promptPassphrase = func(prompt string) ([]byte, error) {
+		prompts = append(prompts, prompt)
+		passphrase := entered[0]
+		entered = entered[1:]
+		return []byte(passphrase), nil
+	}
+
+	// The passphrase for a new file is confirmed, then asked for only once
+	for _, name := range []string{"a", "b", "c"} {
+		store, err := NewSecretStore(SecretStoreFile, dir)
+		require.NoError(t, err)
+		require.NoError(t, store.Set(name, Secrets{APIKey: name}))
+	}
+
+	assert.Equal(t, []string{"Secrets file passphrase: ", "Confirm passphrase: "}, prompts)
+
+	// A wrong passphrase is asked for again
+	filePassphrases = map[string][]byte{}
+	entered = []string{"wrong", "correct horse"}
+
+	store, err := NewSecretStore(SecretStoreFile, dir)
+	require.NoError(t, err)
+
+	_, err = store.Get("a")
+	assert.Error(t, err)
+
+	result, err := store.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", result.APIKey)
+
+	// A mistyped confirmation is refused
+	filePassphrases = map[string][]byte{}
+	entered = []string{"correct horse", "correct hrose"}
+
+	_, err = readNewPassphrase("Bundle passphrase: ")
+	assert.Error(t, err)
+}
+
+func TestMigrateSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	os.Setenv(PassphraseEnvVar, "correct horse")
+	defer os.Unsetenv(PassphraseEnvVar)
+
+	c := &Credentials{
+		Profiles: map[string]Profile{
+			"testProfile": {APIKey: "NRAK-123", Region: "US", AccountID: 1},
+		},
+		DefaultProfile:  "testProfile",
+		ConfigDirectory: dir,
+	}
+
+	require.NoError(t, c.MigrateSecrets("testProfile", SecretStoreFile))
+	assert.Error(t, c.MigrateSecrets("missing", SecretStoreFile))
+	assert.Error(t, c.MigrateSecrets("testProfile", "unknown"))
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, DefaultCredentialsFile+".json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "NRAK-123")
+	assert.Contains(t, string(content), `"secretStore": "file"`)
+
+	// Secrets are resolved for the active profile
+	p, err := c.ActiveProfile()
+	require.NoError(t, err)
+	assert.Equal(t, "NRAK-123", p.APIKey)
+	assert.Equal(t, 1, p.AccountID)
+	assert.Equal(t, "", c.Profiles["testProfile"].APIKey)
+}
@@ -1,10 +1,13 @@
 package diagnose
 
 import (
+	"net/http"
 	"os/exec"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
 )
 
 var cmdUpdate = &cobra.Command{
@@ -24,10 +27,12 @@ Checks the currently-installed version against the latest version, and if they a
 			// Unexpected error
 			log.Fatal(err)
 		}
-		err = downloadBinary()
-		if err != nil {
-			log.Fatal(err)
-		}
+		client.WithHTTPClient(func(httpClient *http.Client) {
+			err = downloadBinary(httpClient)
+			if err != nil {
+				log.Fatal(err)
+			}
+		})
 	},
 }
 
@@ -12,12 +12,13 @@ import (
 	"path"
 	"runtime"
 
+	"github.com/newrelic/newrelic-cli/internal/client"
 	"github.com/newrelic/newrelic-cli/internal/utils"
 
 	log "github.com/sirupsen/logrus"
 )
 
-func downloadBinary() error {
+func downloadBinary(httpClient *http.Client) error {
 	log.Info("Determining OS...")
 	var executable string
 	if bits.UintSize == 64 {
@@ -39,7 +40,7 @@ func downloadBinary() error {
 	}
 
 	log.Infof("Downloading %s", downloadURL)
-	resp, err := http.Get(downloadURL)
+	resp, err := httpClient.Get(downloadURL)
 	if err != nil {
 		log.Warnf("failed to download the latest nrdiag: %s", err)
 		home, _ := utils.GetDefaultConfigDirectory()
@@ -106,7 +107,10 @@ func ensureBinaryExists() error {
 
 	if _, err = os.Stat(destination); os.IsNotExist(err) {
 		log.Infof("nrdiag binary not found in %s", destination)
-		return downloadBinary()
+		client.WithHTTPClient(func(httpClient *http.Client) {
+			err = downloadBinary(httpClient)
+		})
+		return err
 	}
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/output"
 	"github.com/newrelic/newrelic-cli/internal/utils"
 	"github.com/newrelic/newrelic-client-go/newrelic"
@@ -12,7 +13,6 @@ import (
 )
 
 var (
-	accountID            int
 	id                   int
 	name                 string
 	providerRegion       string
@@ -44,7 +44,7 @@ The list command retrieves the trace observers for the given account ID.
 	Example: `newrelic edge trace-observer list --accountId 12345678`,
 	Run: func(cmd *cobra.Command, args []string) {
 		client.WithClient(func(nrClient *newrelic.NewRelic) {
-			traceObservers, err := nrClient.Edge.ListTraceObservers(accountID)
+			traceObservers, err := nrClient.Edge.ListTraceObservers(credentials.RequireAccountID())
 			utils.LogIfFatal(err)
 
 			utils.LogIfFatal(output.Print(traceObservers))
@@ -77,7 +77,7 @@ Valid provider regions are AWS_US_EAST_1 and AWS_US_EAST_2.
 				log.Fatalf("%s is not a valid provider region, valid values are %s", providerRegion, validProviderRegions)
 			}
 
-			traceObserver, err := nrClient.Edge.CreateTraceObserver(accountID, name, edge.EdgeProviderRegion(providerRegion))
+			traceObserver, err := nrClient.Edge.CreateTraceObserver(credentials.RequireAccountID(), name, edge.EdgeProviderRegion(providerRegion))
 			utils.LogIfFatal(err)
 
 			utils.LogIfFatal(output.Print(traceObserver))
@@ -96,7 +96,7 @@ The delete command accepts a trace observer's ID.
 	Example: `newrelic edge trace-observer delete --accountId 12345678 --id 1234`,
 	Run: func(cmd *cobra.Command, args []string) {
 		client.WithClient(func(nrClient *newrelic.NewRelic) {
-			_, err := nrClient.Edge.DeleteTraceObserver(accountID, id)
+			_, err := nrClient.Edge.DeleteTraceObserver(credentials.RequireAccountID(), id)
 			utils.LogIfFatal(err)
 
 			log.Info("success")
@@ -107,8 +107,7 @@ The delete command accepts a trace observer's ID.
 func init() {
 	// Root sub-command
 	Command.AddCommand(cmdTraceObserver)
-	cmdTraceObserver.PersistentFlags().IntVarP(&accountID, "accountId", "a", 0, "A New Relic account ID")
-	utils.LogIfError(cmdTraceObserver.MarkPersistentFlagRequired("accountId"))
+	credentials.AddAccountIDFlag(cmdTraceObserver.PersistentFlags(), "A New Relic account ID")
 
 	// List
 	cmdTraceObserver.AddCommand(cmdList)
@@ -2,6 +2,7 @@ package entities
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -10,6 +11,7 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/client"
 	"github.com/newrelic/newrelic-cli/internal/output"
 	"github.com/newrelic/newrelic-cli/internal/pipe"
+	"github.com/newrelic/newrelic-cli/internal/undo"
 	"github.com/newrelic/newrelic-cli/internal/utils"
 	"github.com/newrelic/newrelic-client-go/newrelic"
 	"github.com/newrelic/newrelic-client-go/pkg/entities"
@@ -66,9 +68,14 @@ that match the specified keys.
 	Example: "newrelic entity tags delete --guid <entityGUID> --tag tag1 --tag tag2 --tag tag3,tag4",
 	Run: func(cmd *cobra.Command, args []string) {
 		client.WithClient(func(nrClient *newrelic.NewRelic) {
-			_, err := nrClient.Entities.TaggingDeleteTagFromEntity(entities.EntityGUID(entityGUID), entityTags)
+			description := fmt.Sprintf("delete tags %s from entity %s", strings.Join(entityTags, ","), entityGUID)
+			snapshot, err := undo.SnapshotTags(nrClient, entities.EntityGUID(entityGUID), description)
 			utils.LogIfFatal(err)
 
+			_, err = nrClient.Entities.TaggingDeleteTagFromEntity(entities.EntityGUID(entityGUID), entityTags)
+			utils.LogIfFatal(err)
+			utils.LogIfFatal(undo.Record(snapshot))
+
 			log.Info("success")
 		})
 	},
@@ -87,8 +94,13 @@ The delete-values command deletes the specified tag:value pairs on a given entit
 			tagValues, err := assembleTagValuesInput(entityValues)
 			utils.LogIfFatal(err)
 
+			description := fmt.Sprintf("delete tag values %s from entity %s", strings.Join(entityValues, ","), entityGUID)
+			snapshot, err := undo.SnapshotTags(nrClient, entities.EntityGUID(entityGUID), description)
+			utils.LogIfFatal(err)
+
 			_, err = nrClient.Entities.TaggingDeleteTagValuesFromEntity(entities.EntityGUID(entityGUID), tagValues)
 			utils.LogIfFatal(err)
+			utils.LogIfFatal(undo.Record(snapshot))
 
 			log.Info("success")
 		})
@@ -130,8 +142,13 @@ provided for the given entity.
 			tags, err := assembleTagsInput(entityTags)
 			utils.LogIfFatal(err)
 
+			description := fmt.Sprintf("replace tags on entity %s with %s", entityGUID, strings.Join(entityTags, ","))
+			snapshot, err := undo.SnapshotTags(nrClient, entities.EntityGUID(entityGUID), description)
+			utils.LogIfFatal(err)
+
 			_, err = nrClient.Entities.TaggingReplaceTagsOnEntity(entities.EntityGUID(entityGUID), tags)
 			utils.LogIfFatal(err)
+			utils.LogIfFatal(undo.Record(snapshot))
 
 			log.Info("success")
 		})
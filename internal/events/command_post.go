@@ -13,8 +13,7 @@ import (
 )
 
 var (
-	accountID int
-	event     string
+	event string
 )
 
 var cmdPost = &cobra.Command{
@@ -30,6 +29,8 @@ represents the custom event's type.
 `,
 	Example: `newrelic events post --accountId 12345 --event '{ "eventType": "Payment", "amount": 123.45 }'`,
 	Run: func(cmd *cobra.Command, args []string) {
+		accountID := credentials.RequireAccountID()
+
 		client.WithClientAndProfile(func(nrClient *newrelic.NewRelic, profile *credentials.Profile) {
 			if profile.InsightsInsertKey == "" {
 				log.Fatal("an Insights insert key is required, set one in your default profile or use the NEW_RELIC_INSIGHTS_INSERT_KEY environment variable")
@@ -53,8 +54,7 @@ represents the custom event's type.
 
 func init() {
 	Command.AddCommand(cmdPost)
-	cmdPost.Flags().IntVarP(&accountID, "accountId", "a", 0, "the account ID to create the custom event in")
+	credentials.AddAccountIDFlag(cmdPost.Flags(), "the account ID to create the custom event in")
 	cmdPost.Flags().StringVarP(&event, "event", "e", "{}", "a JSON-formatted event payload to post")
-	utils.LogIfError(cmdPost.MarkFlagRequired("accountId"))
 	utils.LogIfError(cmdPost.MarkFlagRequired("event"))
 }
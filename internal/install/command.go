@@ -30,35 +30,52 @@ var Command = &cobra.Command{
 	Short:  "Install New Relic.",
 	Hidden: true,
 	Run: func(cmd *cobra.Command, args []string) {
-		ic := InstallerContext{
-			AssumeYes:          assumeYes,
-			RecipeNames:        recipeNames,
-			RecipePaths:        recipePaths,
-			SkipDiscovery:      skipDiscovery,
-			SkipIntegrations:   skipIntegrations,
-			SkipLoggingInstall: skipLoggingInstall,
-		}
+		config.WithConfig(func(cfg *config.Config) {
+			// Fall back to the recipes set in the CLI configuration
+			if len(recipePaths) == 0 {
+				recipePaths = cfg.Install.RecipePaths
+			}
 
-		client.WithClientAndProfile(func(nrClient *newrelic.NewRelic, profile *credentials.Profile) {
-			if trace {
-				log.SetLevel(log.TraceLevel)
-				nrClient.SetLogLevel("trace")
-			} else if debug {
-				log.SetLevel(log.DebugLevel)
-				nrClient.SetLogLevel("debug")
+			if len(recipeNames) == 0 {
+				recipeNames = cfg.Install.RecipeNames
 			}
 
-			err := assertProfileIsValid(profile)
-			if err != nil {
-				log.Fatal(err)
+			ic := InstallerContext{
+				AssumeYes:          assumeYes,
+				RecipeNames:        recipeNames,
+				RecipePaths:        recipePaths,
+				SkipDiscovery:      skipDiscovery,
+				SkipIntegrations:   skipIntegrations,
+				SkipLoggingInstall: skipLoggingInstall,
 			}
 
-			i := NewRecipeInstaller(ic, nrClient)
+			client.WithClientAndProfile(func(nrClient *newrelic.NewRelic, profile *credentials.Profile) {
+				if trace {
+					log.SetLevel(log.TraceLevel)
+					nrClient.SetLogLevel("trace")
+				} else if debug {
+					log.SetLevel(log.DebugLevel)
+					nrClient.SetLogLevel("debug")
+				}
 
-			// Run the install.
-			if err := i.Install(); err != nil {
-				log.Fatalf("Could not install New Relic: %s, check the install log for details: %s", err, config.DefaultLogFile)
-			}
+				err := assertProfileIsValid(profile)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				// Recipe files are downloaded with the same proxy and TLS settings
+				httpClient, err := client.NewHTTPClient(cfg, profile)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				i := NewRecipeInstaller(ic, nrClient, httpClient)
+
+				// Run the install.
+				if err := i.Install(); err != nil {
+					log.Fatalf("Could not install New Relic: %s, check the install log for details: %s", err, config.DefaultLogFile)
+				}
+			})
 		})
 	},
 }
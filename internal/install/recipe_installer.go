@@ -3,6 +3,7 @@ package install
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 
 	log "github.com/sirupsen/logrus"
@@ -35,10 +36,10 @@ type RecipeInstaller struct {
 	progressIndicator ux.ProgressIndicator
 }
 
-func NewRecipeInstaller(ic InstallerContext, nrClient *newrelic.NewRelic) *RecipeInstaller {
+func NewRecipeInstaller(ic InstallerContext, nrClient *newrelic.NewRelic, httpClient *http.Client) *RecipeInstaller {
 	rf := recipes.NewServiceRecipeFetcher(&nrClient.NerdGraph)
 	pf := discovery.NewRegexProcessFilterer(rf)
-	ff := recipes.NewRecipeFileFetcher()
+	ff := recipes.NewRecipeFileFetcherWithClient(httpClient)
 	ers := []execution.StatusReporter{
 		execution.NewNerdStorageStatusReporter(&nrClient.NerdStorage),
 		execution.NewTerminalStatusReporter(),
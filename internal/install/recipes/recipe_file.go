@@ -66,6 +66,15 @@ func NewRecipeFileFetcher() RecipeFileFetcher {
 	return &f
 }
 
+// NewRecipeFileFetcherWithClient returns a fetcher which downloads recipe
+// files with the given HTTP client, for its proxy and TLS settings.
+func NewRecipeFileFetcherWithClient(c *http.Client) RecipeFileFetcher {
+	f := RecipeFileFetcherImpl{}
+	f.HTTPGetFunc = c.Get
+	f.readFileFunc = defaultReadFileFunc
+	return &f
+}
+
 func defaultHTTPGetFunc(recipeURL string) (*http.Response, error) {
 	return http.Get(recipeURL)
 }
@@ -1,11 +1,15 @@
 package nerdstorage
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/undo"
 )
 
 var (
-	accountID  int
 	entityGUID string
 	packageID  string
 	collection string
@@ -19,3 +23,21 @@ var Command = &cobra.Command{
 	Use:   "nerdstorage",
 	Short: "Read, write, and delete NerdStorage documents and collections.",
 }
+
+// documentSnapshot returns the location of the documents given on the
+// command line, for saving them before they are changed
+func documentSnapshot() *undo.DocumentsSnapshot {
+	s := &undo.DocumentsSnapshot{
+		Scope:      scope,
+		EntityGUID: entityGUID,
+		PackageID:  packageID,
+		Collection: collection,
+		DocumentID: documentID,
+	}
+
+	if strings.EqualFold(scope, "account") {
+		s.AccountID = credentials.RequireAccountID()
+	}
+
+	return s
+}
@@ -1,13 +1,16 @@
 package nerdstorage
 
 import (
+	"fmt"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/undo"
 	"github.com/newrelic/newrelic-cli/internal/utils"
 	"github.com/newrelic/newrelic-client-go/newrelic"
 	"github.com/newrelic/newrelic-client-go/pkg/nerdstorage"
@@ -51,7 +54,7 @@ GUID.  A valid Nerdpack package ID is required.
 
 			switch strings.ToLower(scope) {
 			case "account":
-				resp, err = nrClient.NerdStorage.GetCollectionWithAccountScope(accountID, input)
+				resp, err = nrClient.NerdStorage.GetCollectionWithAccountScope(credentials.RequireAccountID(), input)
 			case "entity":
 				resp, err = nrClient.NerdStorage.GetCollectionWithEntityScope(entityGUID, input)
 			case "user":
@@ -97,9 +100,13 @@ GUID.  A valid Nerdpack package ID is required.
 				Collection: collection,
 			}
 
+			description := fmt.Sprintf("delete collection %s", collection)
+			snapshot, err := undo.SnapshotCollection(nrClient, documentSnapshot(), description)
+			utils.LogIfFatal(err)
+
 			switch strings.ToLower(scope) {
 			case "account":
-				_, err = nrClient.NerdStorage.DeleteCollectionWithAccountScope(accountID, input)
+				_, err = nrClient.NerdStorage.DeleteCollectionWithAccountScope(credentials.RequireAccountID(), input)
 			case "entity":
 				_, err = nrClient.NerdStorage.DeleteCollectionWithEntityScope(entityGUID, input)
 			case "user":
@@ -111,6 +118,8 @@ GUID.  A valid Nerdpack package ID is required.
 				log.Fatal(err)
 			}
 
+			utils.LogIfFatal(undo.Record(snapshot))
+
 			log.Info("success")
 		})
 	},
@@ -120,7 +129,7 @@ func init() {
 	Command.AddCommand(cmdCollection)
 
 	cmdCollection.AddCommand(cmdCollectionGet)
-	cmdCollectionGet.Flags().IntVarP(&accountID, "accountId", "a", 0, "the account ID")
+	credentials.AddAccountIDFlag(cmdCollectionGet.Flags(), "the account ID")
 	cmdCollectionGet.Flags().StringVarP(&entityGUID, "entityGuid", "e", "", "the entity GUID")
 	cmdCollectionGet.Flags().StringVarP(&packageID, "packageId", "p", "", "the external package ID")
 	cmdCollectionGet.Flags().StringVarP(&collection, "collection", "c", "", "the collection name to get the document from")
@@ -136,7 +145,7 @@ func init() {
 	utils.LogIfError(err)
 
 	cmdCollection.AddCommand(cmdCollectionDelete)
-	cmdCollectionDelete.Flags().IntVarP(&accountID, "accountId", "a", 0, "the account ID")
+	credentials.AddAccountIDFlag(cmdCollectionDelete.Flags(), "the account ID")
 	cmdCollectionDelete.Flags().StringVarP(&entityGUID, "entityGuid", "e", "", "the entity GUID")
 	cmdCollectionDelete.Flags().StringVarP(&packageID, "packageId", "", "p", "the external package ID")
 	cmdCollectionDelete.Flags().StringVarP(&collection, "collection", "c", "", "the collection name to delete the document from")
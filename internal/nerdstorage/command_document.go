@@ -2,13 +2,16 @@ package nerdstorage
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/undo"
 	"github.com/newrelic/newrelic-cli/internal/utils"
 	"github.com/newrelic/newrelic-client-go/newrelic"
 	"github.com/newrelic/newrelic-client-go/pkg/nerdstorage"
@@ -53,7 +56,7 @@ GUID.  A valid Nerdpack package ID is required.
 
 			switch strings.ToLower(scope) {
 			case "account":
-				document, err = nrClient.NerdStorage.GetDocumentWithAccountScope(accountID, input)
+				document, err = nrClient.NerdStorage.GetDocumentWithAccountScope(credentials.RequireAccountID(), input)
 			case "entity":
 				document, err = nrClient.NerdStorage.GetDocumentWithEntityScope(entityGUID, input)
 			case "user":
@@ -105,9 +108,13 @@ GUID.  A valid Nerdpack package ID is required.
 				Document:   unmarshaled,
 			}
 
+			description := fmt.Sprintf("write document %s in collection %s", documentID, collection)
+			snapshot, err := undo.SnapshotDocument(nrClient, documentSnapshot(), description)
+			utils.LogIfFatal(err)
+
 			switch strings.ToLower(scope) {
 			case "account":
-				_, err = nrClient.NerdStorage.WriteDocumentWithAccountScope(accountID, input)
+				_, err = nrClient.NerdStorage.WriteDocumentWithAccountScope(credentials.RequireAccountID(), input)
 			case "entity":
 				_, err = nrClient.NerdStorage.WriteDocumentWithEntityScope(entityGUID, input)
 			case "user":
@@ -119,6 +126,8 @@ GUID.  A valid Nerdpack package ID is required.
 				log.Fatal(err)
 			}
 
+			utils.LogIfFatal(undo.Record(snapshot))
+
 			log.Info("success")
 		})
 	},
@@ -153,9 +162,13 @@ GUID.  A valid Nerdpack package ID is required.
 				DocumentID: documentID,
 			}
 
+			description := fmt.Sprintf("delete document %s from collection %s", documentID, collection)
+			snapshot, err := undo.SnapshotDocument(nrClient, documentSnapshot(), description)
+			utils.LogIfFatal(err)
+
 			switch strings.ToLower(scope) {
 			case "account":
-				_, err = nrClient.NerdStorage.DeleteDocumentWithAccountScope(accountID, input)
+				_, err = nrClient.NerdStorage.DeleteDocumentWithAccountScope(credentials.RequireAccountID(), input)
 			case "entity":
 				_, err = nrClient.NerdStorage.DeleteDocumentWithEntityScope(entityGUID, input)
 			case "user":
@@ -167,6 +180,8 @@ GUID.  A valid Nerdpack package ID is required.
 				log.Fatal(err)
 			}
 
+			utils.LogIfFatal(undo.Record(snapshot))
+
 			log.Info("success")
 		})
 	},
@@ -176,7 +191,7 @@ func init() {
 	Command.AddCommand(cmdDocument)
 
 	cmdDocument.AddCommand(cmdDocumentGet)
-	cmdDocumentGet.Flags().IntVarP(&accountID, "accountId", "a", 0, "the account ID")
+	credentials.AddAccountIDFlag(cmdDocumentGet.Flags(), "the account ID")
 	cmdDocumentGet.Flags().StringVarP(&entityGUID, "entityGuid", "e", "", "the entity GUID")
 	cmdDocumentGet.Flags().StringVarP(&packageID, "packageId", "p", "", "the external package ID")
 	cmdDocumentGet.Flags().StringVarP(&collection, "collection", "c", "", "the collection name to get the document from")
@@ -196,7 +211,7 @@ func init() {
 	utils.LogIfError(err)
 
 	cmdDocument.AddCommand(cmdDocumentWrite)
-	cmdDocumentWrite.Flags().IntVarP(&accountID, "accountId", "a", 0, "the account ID")
+	credentials.AddAccountIDFlag(cmdDocumentWrite.Flags(), "the account ID")
 	cmdDocumentWrite.Flags().StringVarP(&entityGUID, "entityGuid", "e", "", "the entity GUID")
 	cmdDocumentWrite.Flags().StringVarP(&packageID, "packageId", "p", "", "the external package ID")
 	cmdDocumentWrite.Flags().StringVarP(&collection, "collection", "c", "", "the collection name to write the document to")
@@ -220,7 +235,7 @@ func init() {
 	utils.LogIfError(err)
 
 	cmdDocument.AddCommand(cmdDocumentDelete)
-	cmdDocumentDelete.Flags().IntVarP(&accountID, "accountId", "a", 0, "the account ID")
+	credentials.AddAccountIDFlag(cmdDocumentDelete.Flags(), "the account ID")
 	cmdDocumentDelete.Flags().StringVarP(&entityGUID, "entityGuid", "e", "", "the entity GUID")
 	cmdDocumentDelete.Flags().StringVarP(&packageID, "packageId", "p", "", "the external package ID")
 	cmdDocumentDelete.Flags().StringVarP(&collection, "collection", "c", "", "the collection name to delete the document from")
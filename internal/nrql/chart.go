@@ -0,0 +1,207 @@
+package nrql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+var errNotChartable = errors.New("only the results of TIMESERIES or FACET queries can be charted")
+
+// Fields of a result which describe it rather than hold a value to chart
+var chartIgnoredFields = map[string]bool{
+	"accountId":        true,
+	"beginTimeSeconds": true,
+	"endTimeSeconds":   true,
+	"facet":            true,
+}
+
+// chartSeries returns the series to chart from the results of a query: one
+// for each facet and function of a TIMESERIES query, or one for each
+// function of a FACET query, with a value for each facet.  The attributes
+// faceted by are given by facets.
+func chartSeries(results []nrdb.NRDBResult, facets []string) ([]output.Series, error) {
+	if len(results) == 0 {
+		return nil, errNotChartable
+	}
+
+	if _, ok := results[0]["beginTimeSeconds"]; ok {
+		return timeseries(results, facets), nil
+	}
+
+	if _, ok := results[0]["facet"]; ok {
+		return facetSeries(results, facets), nil
+	}
+
+	return nil, errNotChartable
+}
+
+func timeseries(results []nrdb.NRDBResult, facets []string) []output.Series {
+	layout := timeLayout(results)
+
+	functions := map[string]bool{}
+	for _, r := range results {
+		for name := range chartValues(r, facets) {
+			functions[name] = true
+		}
+	}
+
+	series := []output.Series{}
+	byName := map[string]int{}
+
+	for _, r := range results {
+		facet := facetName(r)
+		label := time.Unix(int64(toFloat(r["beginTimeSeconds"])), 0).Format(layout)
+
+		values := chartValues(r, facets)
+		for _, function := range sortedKeys(values) {
+			// Series are named after the facet when the query has one
+			name := function
+			switch {
+			case facet != "" && len(functions) > 1:
+				name = facet + " " + function
+			case facet != "":
+				name = facet
+			}
+
+			i, ok := byName[name]
+			if !ok {
+				i = len(series)
+				byName[name] = i
+				series = append(series, output.Series{Name: name})
+			}
+
+			series[i].Labels = append(series[i].Labels, label)
+			series[i].Values = append(series[i].Values, values[function])
+		}
+	}
+
+	return series
+}
+
+func facetSeries(results []nrdb.NRDBResult, facets []string) []output.Series {
+	series := []output.Series{}
+	byName := map[string]int{}
+
+	for _, r := range results {
+		facet := facetName(r)
+
+		values := chartValues(r, facets)
+		for _, function := range sortedKeys(values) {
+			i, ok := byName[function]
+			if !ok {
+				i = len(series)
+				byName[function] = i
+				series = append(series, output.Series{Name: function})
+			}
+
+			series[i].Labels = append(series[i].Labels, facet)
+			series[i].Values = append(series[i].Values, values[function])
+		}
+	}
+
+	return series
+}
+
+// chartValues returns the numeric values of a result, named after their
+// function.  Functions with several values, such as percentile(), are
+// named after each of them.  The attributes faceted by, which are repeated
+// alongside the facet, are left out.
+func chartValues(r nrdb.NRDBResult, facets []string) map[string]float64 {
+	values := map[string]float64{}
+
+	faceted := map[string]bool{}
+	for _, f := range facets {
+		faceted[f] = true
+	}
+
+	var add func(name string, v interface{})
+	add = func(name string, v interface{}) {
+		switch value := v.(type) {
+		case float64, int, int64, json.Number:
+			values[name] = toFloat(value)
+		case map[string]interface{}:
+			for k, nested := range value {
+				add(name+"."+k, nested)
+			}
+		}
+	}
+
+	for k, v := range r {
+		if chartIgnoredFields[k] || faceted[k] {
+			continue
+		}
+
+		add(k, v)
+	}
+
+	return values
+}
+
+// facetName returns the facet of a result, joining the values of a facet
+// on several attributes
+func facetName(r nrdb.NRDBResult) string {
+	switch f := r["facet"].(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		names := make([]string, len(f))
+		for i, v := range f {
+			names[i] = fmt.Sprint(v)
+		}
+
+		return strings.Join(names, ", ")
+	default:
+		return fmt.Sprint(f)
+	}
+}
+
+// timeLayout returns how to label the buckets of a timeseries, depending
+// on the time it spans
+func timeLayout(results []nrdb.NRDBResult) string {
+	begin := toFloat(results[0]["beginTimeSeconds"])
+	end := toFloat(results[len(results)-1]["endTimeSeconds"])
+	bucket := toFloat(results[0]["endTimeSeconds"]) - begin
+
+	switch {
+	case bucket >= 24*60*60:
+		return "2006-01-02"
+	case end-begin > 24*60*60:
+		return "01-02 15:04"
+	}
+
+	return "15:04"
+}
+
+func toFloat(v interface{}) float64 {
+	switch value := v.(type) {
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case json.Number:
+		f, _ := value.Float64()
+		return f
+	}
+
+	return 0
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,101 @@
+// +build unit
+
+package nrql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+func TestChartSeriesTimeseries(t *testing.T) {
+	results := []nrdb.NRDBResult{
+		{"beginTimeSeconds": float64(0), "endTimeSeconds": float64(60), "count": float64(1)},
+		{"beginTimeSeconds": float64(60), "endTimeSeconds": float64(120), "count": float64(3)},
+	}
+
+	series, err := chartSeries(results, nil)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	assert.Equal(t, "count", series[0].Name)
+	assert.Equal(t, []float64{1, 3}, series[0].Values)
+	assert.Len(t, series[0].Labels, 2)
+}
+
+func TestChartSeriesFacetedTimeseries(t *testing.T) {
+	results := []nrdb.NRDBResult{
+		{"beginTimeSeconds": float64(0), "endTimeSeconds": float64(60), "facet": "web", "appName": "web", "count": float64(1), "percentile.duration": map[string]interface{}{"95": 0.5}},
+		{"beginTimeSeconds": float64(0), "endTimeSeconds": float64(60), "facet": "worker", "appName": "worker", "count": float64(2), "percentile.duration": map[string]interface{}{"95": 0.7}},
+		{"beginTimeSeconds": float64(60), "endTimeSeconds": float64(120), "facet": "web", "appName": "web", "count": float64(5), "percentile.duration": map[string]interface{}{"95": 0.6}},
+	}
+
+	series, err := chartSeries(results, []string{"appName"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []output.Series{
+		{Name: "web count", Labels: series[0].Labels, Values: []float64{1, 5}},
+		{Name: "web percentile.duration.95", Labels: series[1].Labels, Values: []float64{0.5, 0.6}},
+		{Name: "worker count", Labels: series[2].Labels, Values: []float64{2}},
+		{Name: "worker percentile.duration.95", Labels: series[3].Labels, Values: []float64{0.7}},
+	}, series)
+}
+
+func TestChartSeriesFacet(t *testing.T) {
+	results := []nrdb.NRDBResult{
+		{"facet": []interface{}{"web", float64(200)}, "appName": "web", "httpResponseCode": float64(200), "count": float64(10)},
+		{"facet": []interface{}{"worker", float64(500)}, "appName": "worker", "count": float64(4)},
+	}
+
+	series, err := chartSeries(results, []string{"appName", "httpResponseCode"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []output.Series{
+		{Name: "count", Labels: []string{"web, 200", "worker, 500"}, Values: []float64{10, 4}},
+	}, series)
+}
+
+func TestChartSeriesValueEqualsFacet(t *testing.T) {
+	results := []nrdb.NRDBResult{
+		{"facet": "200", "httpResponseCode": "200", "count": float64(200)},
+		{"facet": "404", "httpResponseCode": "404", "count": float64(3)},
+	}
+
+	// Values are charted even when they match their facet
+	series, err := chartSeries(results, []string{"httpResponseCode"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []output.Series{
+		{Name: "count", Labels: []string{"200", "404"}, Values: []float64{200, 3}},
+	}, series)
+}
+
+func TestChartSeriesNotChartable(t *testing.T) {
+	_, err := chartSeries([]nrdb.NRDBResult{{"count": float64(1)}}, nil)
+	assert.Error(t, err)
+
+	_, err = chartSeries(nil, nil)
+	assert.Error(t, err)
+}
+
+func TestTimeLayout(t *testing.T) {
+	minutes := []nrdb.NRDBResult{
+		{"beginTimeSeconds": float64(0), "endTimeSeconds": float64(60)},
+		{"beginTimeSeconds": float64(60), "endTimeSeconds": float64(120)},
+	}
+	assert.Equal(t, "15:04", timeLayout(minutes))
+
+	hours := []nrdb.NRDBResult{
+		{"beginTimeSeconds": float64(0), "endTimeSeconds": float64(3600)},
+		{"beginTimeSeconds": float64(3 * 86400), "endTimeSeconds": float64(3*86400 + 3600)},
+	}
+	assert.Equal(t, "01-02 15:04", timeLayout(hours))
+
+	days := []nrdb.NRDBResult{
+		{"beginTimeSeconds": float64(0), "endTimeSeconds": float64(86400)},
+	}
+	assert.Equal(t, "2006-01-02", timeLayout(days))
+}
@@ -1,10 +1,13 @@
 package nrql
 
 import (
+	"time"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/output"
 	"github.com/newrelic/newrelic-cli/internal/utils"
 	"github.com/newrelic/newrelic-client-go/newrelic"
@@ -12,9 +15,17 @@ import (
 )
 
 var (
-	accountID    int
-	historyLimit int
-	query        string
+	allAccounts     bool
+	chart           string
+	concurrency     int
+	historyLimit    int
+	query           string
+	queryAccountIDs []int
+	queryFile       string
+	queryVarPairs   []string
+	queryVarsFile   string
+	thresholdExpr   string
+	watchInterval   time.Duration
 )
 
 var cmdQuery = &cobra.Command{
@@ -22,24 +33,201 @@ var cmdQuery = &cobra.Command{
 	Short: "Execute a NRQL query to New Relic",
 	Long: `Execute a NRQL query to New Relic
 
-The query command requires the --query flag which represents a NRQL query string.
-This command requires the --accountId <int> flag, which specifies the account to
-issue the query against.
+The query command requires either the --query flag which represents a NRQL query
+string, or the --file flag naming a file of NRQL queries.  The --accountId <int>
+flag specifies the account to issue the query against, and defaults to the account
+ID of the profile in use.
+
+A file may hold several queries separated by semicolons, which are run in turn.
+Lines starting with -- or // are comments, and the comment directly above a
+query names it in the results, which list the name, query and results of each.
+Queries may have parameters such as {{.appName}}, which are filled in from a
+YAML or JSON file given by --vars-file, and from --var key=value flags, which
+take precedence.  A --query is filled in the same way when --var or --vars-file
+is given.
+
+The --accountIds flag runs the queries against each of the given accounts, and
+--all-accounts against every account you can view.  The accounts are queried
+concurrently, at most --concurrency at a time, and their results are merged,
+with the accountId of each result added to it, unless the query returns one.
+An account whose query fails is reported without stopping the others, and the
+command then exits with an error.
+
+The --chart <style> flag draws the results of a TIMESERIES or FACET query as a
+chart sized to the terminal, instead of printing them.  The line style plots
+every series on the same axes, bar draws a bar for each bucket or facet, and
+sparkline draws each series on a single line.  Each series is labelled by its
+facet, and followed by its minimum, maximum and average.
+
+The --watch <interval> flag runs the query again every interval, redrawing its
+results in place until interrupted.  Results are printed as a table, unless
+another format is chosen, with the values which changed since the last run
+highlighted.
+
+The --threshold flag exits with an error when a value of the results meets a
+comparison such as 'count > 100', which makes the query usable as a deploy gate.
+Only the latest bucket of a TIMESERIES query is compared, and any facet may meet
+the threshold.  Field names with spaces are quoted with backticks.
 `,
-	Example: `newrelic nrql query --accountId 12345678 --query 'SELECT count(*) FROM Transaction TIMESERIES'`,
+	Example: `newrelic nrql query --accountId 12345678 --query 'SELECT count(*) FROM Transaction TIMESERIES'
+newrelic nrql query --query 'SELECT average(duration) FROM Transaction FACET appName TIMESERIES' --chart sparkline
+newrelic nrql query --query 'SELECT count(*) FROM TransactionError SINCE 5 minutes ago' --watch 30s --threshold 'count > 100'
+newrelic nrql query --file queries.nrql --var appName=checkout --var since='1 hour ago'
+newrelic nrql query --accountIds 12345678,23456789 --query 'SELECT count(*) FROM Transaction SINCE 1 hour ago'`,
 	Run: func(cmd *cobra.Command, args []string) {
+		checkQueryFlags(cmd)
+
+		fanOut := len(queryAccountIDs) > 0 || allAccounts
+
+		var accountID int
+		if !fanOut {
+			accountID = credentials.RequireAccountID()
+		}
+
+		vars, err := queryVars(queryVarsFile, queryVarPairs)
+		utils.LogIfFatal(err)
+
+		var queries []fileQuery
+		if queryFile != "" {
+			queries, err = readQueryFile(queryFile, vars)
+			utils.LogIfFatal(err)
+		}
+
+		nrql, err := expandQuery(query, vars)
+		utils.LogIfFatal(err)
+
+		var style *output.ChartStyle
+		if chart != "" {
+			s, err := output.ParseChartStyle(chart)
+			utils.LogIfFatal(err)
+			style = &s
+		}
+
+		var limit *threshold
+		if thresholdExpr != "" {
+			limit, err = parseThreshold(thresholdExpr)
+			utils.LogIfFatal(err)
+		}
+
+		if cmd.Flags().Changed("watch") {
+			if watchInterval < minWatchInterval {
+				log.Fatalf("the --watch interval must be at least %s", minWatchInterval)
+			}
+
+			// Watched results are drawn as a table, unless asked otherwise
+			if !cmd.Flags().Changed("format") && !cmd.Flags().Changed("template") && !cmd.Flags().Changed("template-file") {
+				utils.LogIfError(output.SetFormat(output.FormatText))
+			}
+
+			utils.LogIfError(output.SetHighlightChanges(true))
+		}
+
 		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			if watchInterval > 0 {
+				watch(&nrClient.Nrdb, accountID, nrql, watchInterval, style, limit)
+				return
+			}
 
-			result, err := nrClient.Nrdb.Query(accountID, nrdb.NRQL(query))
-			if err != nil {
-				log.Fatal(err)
+			q := &accountsQuerier{querier: &nrClient.Nrdb, accountIDs: []int{accountID}}
+			if fanOut {
+				q.fanOut = true
+				q.concurrency = concurrency
+				q.accountIDs = queryAccountIDs
+
+				if allAccounts {
+					q.accountIDs, err = allAccountIDs(nrClient)
+					utils.LogIfFatal(err)
+				}
+			}
+
+			if queryFile != "" {
+				utils.LogIfFatal(output.Print(runQueries(q, queries)))
+			} else {
+				result, err := q.run(nrql)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				utils.LogIfFatal(printResults(result, style))
+				checkThreshold(limit, result)
 			}
 
-			utils.LogIfFatal(output.Print(result.Results))
+			if len(q.failed) > 0 {
+				log.Fatalf("queries failed for %d of %d accounts, see the errors above", len(q.failed), len(q.accountIDs))
+			}
 		})
 	},
 }
 
+// checkQueryFlags exits with an error when the flags of the query command
+// can't be used together
+func checkQueryFlags(cmd *cobra.Command) {
+	if (query == "") == (queryFile == "") {
+		log.Fatal("one of --query or --file is required")
+	}
+
+	if queryFile != "" && (chart != "" || thresholdExpr != "" || cmd.Flags().Changed("watch")) {
+		log.Fatal("--chart, --threshold and --watch can't be used with --file")
+	}
+
+	if len(queryAccountIDs) > 0 && allAccounts {
+		log.Fatal("--accountIds and --all-accounts can't be used together")
+	}
+
+	if len(queryAccountIDs) > 0 || allAccounts {
+		if cmd.Flags().Changed(credentials.AccountIDFlagName) {
+			log.Fatalf("--%s can't be used with --accountIds or --all-accounts", credentials.AccountIDFlagName)
+		}
+
+		if chart != "" || cmd.Flags().Changed("watch") {
+			log.Fatal("--chart and --watch can't be used with --accountIds or --all-accounts")
+		}
+	}
+
+	// A watch never finishes, so a replaced output file would never be
+	// moved into place
+	if cmd.Flags().Changed("watch") {
+		outputFile, _ := cmd.Flags().GetString("output-file")
+		outputAppend, _ := cmd.Flags().GetBool("output-append")
+
+		if outputFile != "" && outputFile != output.StdoutPath && !outputAppend {
+			log.Fatal("--watch can't be used with --output-file unless --output-append is set")
+		}
+	}
+}
+
+// runQueries runs queries read from a file in turn, stopping at the first
+// which fails
+func runQueries(q *accountsQuerier, queries []fileQuery) []fileResult {
+	results := []fileResult{}
+
+	for _, fq := range queries {
+		r, err := q.run(fq.Query)
+		if err != nil {
+			log.Fatalf("error running %s: %s", fq.Name, err)
+		}
+
+		results = append(results, fileResult{Name: fq.Name, Query: fq.Query, Results: r.Results})
+	}
+
+	return results
+}
+
+// printResults prints the results of a query, or draws them as a chart when
+// a chart style is given
+func printResults(result *nrdb.NRDBResultContainer, style *output.ChartStyle) error {
+	if style == nil {
+		return output.Print(result.Results)
+	}
+
+	series, err := chartSeries(result.Results, result.Metadata.Facets)
+	if err != nil {
+		return err
+	}
+
+	return output.Chart(series, *style)
+}
+
 var cmdHistory = &cobra.Command{
 	Use:   "history",
 	Short: "Retrieve NRQL query history",
@@ -74,11 +262,18 @@ The history command will fetch a list of the most recent NRQL queries you execut
 
 func init() {
 	Command.AddCommand(cmdQuery)
-	cmdQuery.Flags().IntVarP(&accountID, "accountId", "a", 0, "the New Relic account ID where you want to query")
-	utils.LogIfError(cmdQuery.MarkFlagRequired("accountId"))
+	credentials.AddAccountIDFlag(cmdQuery.Flags(), "the New Relic account ID where you want to query")
 
 	cmdQuery.Flags().StringVarP(&query, "query", "q", "", "the NRQL query you want to execute")
-	utils.LogIfError(cmdQuery.MarkFlagRequired("query"))
+	cmdQuery.Flags().StringVar(&queryFile, "file", "", "a file of semicolon separated NRQL queries to execute in turn")
+	cmdQuery.Flags().StringArrayVar(&queryVarPairs, "var", []string{}, "a key=value parameter of the queries, may be repeated")
+	cmdQuery.Flags().StringVar(&queryVarsFile, "vars-file", "", "a YAML or JSON file of parameters of the queries")
+	cmdQuery.Flags().IntSliceVar(&queryAccountIDs, "accountIds", []int{}, "the New Relic account IDs to query, merging their results")
+	cmdQuery.Flags().BoolVar(&allAccounts, "all-accounts", false, "query every account you can view, merging their results")
+	cmdQuery.Flags().IntVar(&concurrency, "concurrency", defaultConcurrency, "the number of accounts to query at once with --accountIds or --all-accounts")
+	cmdQuery.Flags().StringVar(&chart, "chart", "", "draw the results of a TIMESERIES or FACET query as a chart ["+output.ChartStyleOptions()+"]")
+	cmdQuery.Flags().DurationVar(&watchInterval, "watch", 0, "run the query again every interval, e.g. 30s, redrawing the results in place")
+	cmdQuery.Flags().StringVar(&thresholdExpr, "threshold", "", "exit with an error when the results meet a comparison, e.g. 'count > 100'")
 
 	Command.AddCommand(cmdHistory)
 	cmdHistory.Flags().IntVarP(&historyLimit, "limit", "l", 10, "history items to return (default: 10, max: 100)")
@@ -0,0 +1,73 @@
+package nrql
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+)
+
+const (
+	// DefaultShellHistoryFile is the file in the config directory holding
+	// the queries entered in the NRQL shell
+	DefaultShellHistoryFile = "nrql_history"
+
+	// DefaultSchemaCacheDirectory is the directory in the config directory
+	// holding the event types and attributes used for completion
+	DefaultSchemaCacheDirectory = "cache"
+)
+
+var cmdShell = &cobra.Command{
+	Use:   "shell",
+	Short: "Run NRQL queries interactively",
+	Long: `Run NRQL queries interactively
+
+The shell command reads NRQL queries, runs them against the account given by
+--accountId, or the account of the profile in use, and prints their results in
+the chosen output format.  A query may span several lines, and is run once it
+ends with a semicolon or a blank line is entered.
+
+Queries are saved to a history file in the config directory, which can be
+searched with Ctrl-R.  Tab completes NRQL keywords, event types, and the
+attributes of the event types being queried.  Event types and attributes are
+cached in the config directory for a day; enter \refresh to fetch them again.
+
+Enter \account <id> to query another account, \help for the other commands, and
+\quit or Ctrl-D to leave the shell.
+`,
+	Example: `newrelic nrql shell --accountId 12345678`,
+	Run: func(cmd *cobra.Command, args []string) {
+		accountID := credentials.RequireAccountID()
+
+		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			cacheDir := filepath.Join(config.DefaultConfigDirectory, DefaultSchemaCacheDirectory)
+			s := newShell(&nrClient.Nrdb, accountID, cacheDir, os.Stdout)
+
+			rl, err := readline.NewEx(&readline.Config{
+				Prompt:                 shellPrompt,
+				HistoryFile:            filepath.Join(config.DefaultConfigDirectory, DefaultShellHistoryFile),
+				DisableAutoSaveHistory: true,
+				HistorySearchFold:      true,
+				AutoComplete:           &completer{shell: s},
+				InterruptPrompt:        "^C",
+				EOFPrompt:              "exit",
+			})
+			utils.LogIfFatal(err)
+			defer rl.Close()
+
+			utils.LogIfFatal(s.run(rl))
+		})
+	},
+}
+
+func init() {
+	Command.AddCommand(cmdShell)
+	credentials.AddAccountIDFlag(cmdShell.Flags(), "the New Relic account ID to query initially")
+}
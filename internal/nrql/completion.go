@@ -0,0 +1,315 @@
+package nrql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+// schemaCacheTTL is how long the event types and attributes of an account
+// are cached before being fetched again
+const schemaCacheTTL = 24 * time.Hour
+
+var (
+	nrqlKeywords = []string{
+		"SELECT", "FROM", "WHERE", "FACET", "SINCE", "UNTIL", "TIMESERIES", "LIMIT", "OFFSET",
+		"COMPARE WITH", "AS", "AND", "OR", "NOT", "IN", "LIKE", "IS NULL", "IS NOT NULL", "ORDER BY",
+		"ASC", "DESC", "AGO", "AUTO", "MAX", "EXTRAPOLATE", "WITH TIMEZONE", "SHOW EVENT TYPES",
+		"average", "count", "filter", "funnel", "histogram", "keyset", "latest", "max", "min",
+		"percentage", "percentile", "rate", "sum", "uniqueCount", "uniques",
+	}
+
+	fromRegexp = regexp.MustCompile(`(?i)\bFROM\s+([\w, ]+)`)
+)
+
+// nrqlQuerier runs NRQL queries, as the Nrdb client does
+type nrqlQuerier interface {
+	Query(accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error)
+}
+
+// schemaCache holds the event types of an account and the attributes of
+// each event type, for completion.  It is kept in the config directory.
+type schemaCache struct {
+	FetchedAt  time.Time           `json:"fetchedAt"`
+	EventTypes []string            `json:"eventTypes"`
+	Attributes map[string][]string `json:"attributes"`
+
+	path      string
+	accountID int
+	querier   nrqlQuerier
+	mu        sync.Mutex
+
+	// Event types whose attributes couldn't be fetched, which aren't cached
+	failed map[string]bool
+}
+
+// loadSchemaCache reads the cached schema of an account, starting afresh if
+// there is none or it has expired
+func loadSchemaCache(dir string, accountID int, querier nrqlQuerier) *schemaCache {
+	c := &schemaCache{
+		path:      filepath.Join(dir, fmt.Sprintf("nrql-schema-%d.json", accountID)),
+		accountID: accountID,
+		querier:   querier,
+	}
+
+	if content, err := ioutil.ReadFile(c.path); err == nil {
+		if err = json.Unmarshal(content, c); err != nil {
+			log.Debugf("ignoring invalid NRQL schema cache %s: %s", c.path, err)
+		}
+	}
+
+	if time.Since(c.FetchedAt) > schemaCacheTTL || c.Attributes == nil {
+		c.reset()
+	}
+
+	c.failed = map[string]bool{}
+
+	return c
+}
+
+func (c *schemaCache) reset() {
+	c.FetchedAt = time.Time{}
+	c.EventTypes = nil
+	c.Attributes = map[string][]string{}
+	c.failed = map[string]bool{}
+}
+
+// refresh drops the cached schema, so it is fetched again
+func (c *schemaCache) refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reset()
+}
+
+// eventTypes returns the event types of the account, fetching them with
+// SHOW EVENT TYPES when they aren't cached
+func (c *schemaCache) eventTypes() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.EventTypes == nil {
+		types, err := c.fetch("SHOW EVENT TYPES SINCE 1 week ago")
+		if err != nil {
+			// Don't try again until the cache is refreshed
+			log.Debugf("unable to fetch event types: %s", err)
+			c.EventTypes = []string{}
+			return nil
+		}
+
+		c.EventTypes = types
+		c.FetchedAt = time.Now()
+		c.save()
+	}
+
+	return c.EventTypes
+}
+
+// attributes returns the attributes of an event type, fetching them with
+// keyset() when they aren't cached
+func (c *schemaCache) attributes(eventType string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if attrs, ok := c.Attributes[eventType]; ok || c.failed[eventType] {
+		return attrs
+	}
+
+	attrs, err := c.fetch(fmt.Sprintf("SELECT keyset() FROM `%s` SINCE 1 day ago", eventType))
+	if err != nil {
+		log.Debugf("unable to fetch the attributes of %s: %s", eventType, err)
+		c.failed[eventType] = true
+		return nil
+	}
+
+	if c.FetchedAt.IsZero() {
+		c.FetchedAt = time.Now()
+	}
+
+	c.Attributes[eventType] = attrs
+	c.save()
+
+	return attrs
+}
+
+// fetch runs a query and returns the names in its results: the event types
+// of SHOW EVENT TYPES, or the keys of keyset()
+func (c *schemaCache) fetch(query string) ([]string, error) {
+	result, err := c.querier.Query(c.accountID, nrdb.NRQL(query))
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+
+	for _, row := range result.Results {
+		for k, v := range row {
+			switch value := v.(type) {
+			case string:
+				if k == "eventType" || k == "key" {
+					names[value] = true
+				}
+			case []interface{}:
+				if strings.HasSuffix(k, "Keys") {
+					for _, name := range value {
+						names[fmt.Sprint(name)] = true
+					}
+				}
+			}
+		}
+	}
+
+	sorted := []string{}
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+
+	sort.Strings(sorted)
+
+	return sorted, nil
+}
+
+func (c *schemaCache) save() {
+	content, err := json.Marshal(c)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(c.path), 0700)
+	}
+
+	if err == nil {
+		err = ioutil.WriteFile(c.path, content, 0600)
+	}
+
+	if err != nil {
+		log.Debugf("unable to save the NRQL schema cache: %s", err)
+	}
+}
+
+// completer completes NRQL keywords, event types, and the attributes of the
+// event types the statement selects from
+type completer struct {
+	shell *shell
+}
+
+// Do implements readline.AutoCompleter
+func (c *completer) Do(line []rune, pos int) ([][]rune, int) {
+	before := string(line[:pos])
+	prefix := currentWord(before)
+
+	schema := c.shell.schema
+
+	eventTypes := schema.eventTypes()
+
+	candidates := append([]string{}, nrqlKeywords...)
+	candidates = append(candidates, eventTypes...)
+
+	// Attributes are completed once the statement names its event types,
+	// leaving out the word being typed, which may be part of an event type
+	statement := c.shell.statement() + " " + strings.TrimSuffix(before, prefix)
+	for _, eventType := range selectedEventTypes(statement) {
+		if len(eventTypes) == 0 || contains(eventTypes, eventType) {
+			candidates = append(candidates, schema.attributes(eventType)...)
+		}
+	}
+
+	return completions(prefix, candidates), len([]rune(prefix))
+}
+
+// currentWord returns the word being typed at the end of text
+func currentWord(text string) string {
+	runes := []rune(text)
+
+	start := len(runes)
+	for start > 0 && isWordRune(runes[start-1]) {
+		start--
+	}
+
+	return string(runes[start:])
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
+
+// completions returns the rest of each candidate starting with the prefix.
+// Keywords are matched regardless of case, and completed in the case of the
+// prefix.
+func completions(prefix string, candidates []string) [][]rune {
+	if prefix == "" {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	matches := []string{}
+
+	for _, candidate := range candidates {
+		var match string
+
+		switch {
+		case strings.HasPrefix(candidate, prefix):
+			match = candidate
+		case isKeyword(candidate) && strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(prefix)):
+			match = strings.ToUpper(candidate)
+			if strings.ToLower(prefix) == prefix {
+				match = strings.ToLower(candidate)
+			}
+		default:
+			continue
+		}
+
+		if !seen[match] && len(match) > len(prefix) {
+			seen[match] = true
+			matches = append(matches, match)
+		}
+	}
+
+	sort.Strings(matches)
+
+	result := make([][]rune, len(matches))
+	for i, m := range matches {
+		result[i] = []rune(m[len(prefix):])
+	}
+
+	return result
+}
+
+func isKeyword(word string) bool {
+	return contains(nrqlKeywords, word)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectedEventTypes returns the event types named after FROM
+func selectedEventTypes(statement string) []string {
+	var types []string
+
+	for _, m := range fromRegexp.FindAllStringSubmatch(statement, -1) {
+		for _, t := range strings.Split(m[1], ",") {
+			fields := strings.Fields(t)
+			if len(fields) > 0 && !isKeyword(strings.ToUpper(fields[0])) {
+				types = append(types, fields[0])
+			}
+		}
+	}
+
+	return types
+}
@@ -0,0 +1,153 @@
+package nrql
+
+import (
+	"errors"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-client-go/newrelic"
+	"github.com/newrelic/newrelic-client-go/pkg/accounts"
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+// defaultConcurrency is how many accounts are queried at once by default
+const defaultConcurrency = 5
+
+// accountError is the error of a query against one account
+type accountError struct {
+	AccountID int
+	Err       error
+}
+
+// accountsQuerier runs queries against a single account, or fans them out
+// to several accounts
+type accountsQuerier struct {
+	querier     nrqlQuerier
+	accountIDs  []int
+	fanOut      bool
+	concurrency int
+
+	// The accounts whose queries failed
+	failed map[int]bool
+}
+
+// run runs a query.  When fanning out, the errors of each account are logged
+// and counted, and an error is returned only if every account failed.
+func (q *accountsQuerier) run(query string) (*nrdb.NRDBResultContainer, error) {
+	if !q.fanOut {
+		return q.querier.Query(q.accountIDs[0], nrdb.NRQL(query))
+	}
+
+	result, failed := queryAccounts(q.querier, q.accountIDs, query, q.concurrency)
+	logAccountErrors(failed)
+
+	if len(failed) > 0 && len(failed) == len(q.accountIDs) {
+		return nil, errors.New("the query failed for every account")
+	}
+
+	for _, f := range failed {
+		if q.failed == nil {
+			q.failed = map[int]bool{}
+		}
+
+		q.failed[f.AccountID] = true
+	}
+
+	return result, nil
+}
+
+// allAccountIDs returns the IDs of the accounts the user can view
+func allAccountIDs(nrClient *newrelic.NewRelic) ([]int, error) {
+	outlines, err := nrClient.Accounts.ListAccounts(accounts.ListAccountsParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(outlines) == 0 {
+		return nil, errors.New("no accounts found")
+	}
+
+	ids := make([]int, len(outlines))
+	for i, a := range outlines {
+		ids[i] = a.ID
+	}
+
+	return ids, nil
+}
+
+// queryAccounts runs a query against each account, at most concurrency at
+// a time.  Each result is tagged with the accountId it came from, unless the
+// query already returned one, such as with FACET accountId, and the results
+// are merged in the order of the accounts.  Accounts whose query failed are
+// returned with their errors rather than ending the run.  The metadata is
+// that of the first account whose query succeeded.
+func queryAccounts(querier nrqlQuerier, accountIDs []int, query string, concurrency int) (*nrdb.NRDBResultContainer, []accountError) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	perAccount := make([]*nrdb.NRDBResultContainer, len(accountIDs))
+	errs := make([]error, len(accountIDs))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency && w < len(accountIDs); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				result, err := querier.Query(accountIDs[i], nrdb.NRQL(query))
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+
+				for _, r := range result.Results {
+					if _, ok := r["accountId"]; !ok {
+						r["accountId"] = accountIDs[i]
+					}
+				}
+
+				perAccount[i] = result
+			}
+		}()
+	}
+
+	for i := range accountIDs {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	merged := &nrdb.NRDBResultContainer{Results: []nrdb.NRDBResult{}}
+	failed := []accountError{}
+	first := true
+
+	for i, result := range perAccount {
+		if errs[i] != nil {
+			failed = append(failed, accountError{AccountID: accountIDs[i], Err: errs[i]})
+			continue
+		}
+
+		if first {
+			merged.Metadata = result.Metadata
+			first = false
+		}
+
+		merged.Results = append(merged.Results, result.Results...)
+	}
+
+	return merged, failed
+}
+
+// logAccountErrors logs the error of each account whose query failed
+func logAccountErrors(failed []accountError) {
+	for _, f := range failed {
+		log.Errorf("account %d: %s", f.AccountID, f.Err)
+	}
+}
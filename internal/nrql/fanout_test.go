@@ -0,0 +1,110 @@
+// +build unit
+
+package nrql
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+// accountFakeQuerier returns a count of ten times the account ID, failing
+// for the accounts given, and tracks how many queries run at once
+type accountFakeQuerier struct {
+	failing map[int]bool
+
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (f *accountFakeQuerier) Query(accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	f.mu.Lock()
+	f.running++
+	if f.running > f.peak {
+		f.peak = f.running
+	}
+	f.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	f.mu.Lock()
+	f.running--
+	f.mu.Unlock()
+
+	if f.failing[accountID] {
+		return nil, errors.New("not accessible")
+	}
+
+	return &nrdb.NRDBResultContainer{
+		Results:  []nrdb.NRDBResult{{"count": float64(accountID * 10)}},
+		Metadata: nrdb.NRDBMetadata{Facets: []string{"appName"}},
+	}, nil
+}
+
+func TestQueryAccounts(t *testing.T) {
+	querier := &accountFakeQuerier{failing: map[int]bool{3: true}}
+
+	result, failed := queryAccounts(querier, []int{1, 2, 3, 4, 5}, "SELECT count(*) FROM Transaction", 2)
+
+	assert.Equal(t, []nrdb.NRDBResult{
+		{"accountId": 1, "count": float64(10)},
+		{"accountId": 2, "count": float64(20)},
+		{"accountId": 4, "count": float64(40)},
+		{"accountId": 5, "count": float64(50)},
+	}, result.Results)
+	assert.Equal(t, []string{"appName"}, result.Metadata.Facets)
+
+	require.Len(t, failed, 1)
+	assert.Equal(t, 3, failed[0].AccountID)
+	assert.Error(t, failed[0].Err)
+
+	assert.Equal(t, 2, querier.peak)
+}
+
+func TestQueryAccountsKeepsAccountID(t *testing.T) {
+	query := "SELECT count(*) FROM Transaction FACET accountId"
+	querier := &fakeQuerier{
+		results: map[string][]nrdb.NRDBResult{
+			query: {{"accountId": float64(7), "count": float64(10)}},
+		},
+	}
+
+	// An accountId returned by the query is not replaced
+	result, failed := queryAccounts(querier, []int{1}, query, 1)
+	assert.Empty(t, failed)
+	assert.Equal(t, []nrdb.NRDBResult{{"accountId": float64(7), "count": float64(10)}}, result.Results)
+}
+
+func TestAccountsQuerier(t *testing.T) {
+	querier := &accountFakeQuerier{failing: map[int]bool{3: true}}
+
+	// A single account's results aren't tagged
+	q := &accountsQuerier{querier: querier, accountIDs: []int{1}}
+	result, err := q.run("SELECT count(*) FROM Transaction")
+	require.NoError(t, err)
+	assert.Equal(t, []nrdb.NRDBResult{{"count": float64(10)}}, result.Results)
+
+	q = &accountsQuerier{querier: querier, accountIDs: []int{3}}
+	_, err = q.run("SELECT count(*) FROM Transaction")
+	assert.Error(t, err)
+
+	// Failed accounts are counted once, however many queries fail
+	q = &accountsQuerier{querier: querier, accountIDs: []int{1, 3}, fanOut: true, concurrency: 5}
+	for i := 0; i < 2; i++ {
+		result, err = q.run("SELECT count(*) FROM Transaction")
+		require.NoError(t, err)
+		assert.Equal(t, []nrdb.NRDBResult{{"accountId": 1, "count": float64(10)}}, result.Results)
+	}
+	assert.Equal(t, map[int]bool{3: true}, q.failed)
+
+	q = &accountsQuerier{querier: querier, accountIDs: []int{3}, fanOut: true, concurrency: 5}
+	_, err = q.run("SELECT count(*) FROM Transaction")
+	assert.Error(t, err)
+}
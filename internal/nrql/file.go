@@ -0,0 +1,198 @@
+package nrql
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+// fileQuery is a query read from a file, named after the comment above it
+type fileQuery struct {
+	Name  string `json:"name" yaml:"name"`
+	Query string `json:"query" yaml:"query"`
+}
+
+// fileResult is the result of a query read from a file
+type fileResult struct {
+	Name    string            `json:"name" yaml:"name"`
+	Query   string            `json:"query" yaml:"query"`
+	Results []nrdb.NRDBResult `json:"results" yaml:"results"`
+}
+
+// readQueryFile reads the semicolon separated queries of a file, filling in
+// their parameters from vars
+func readQueryFile(path string, vars map[string]interface{}) ([]fileQuery, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := splitQueries(string(content))
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no queries found in %s", path)
+	}
+
+	for i := range queries {
+		if queries[i].Query, err = renderQuery(queries[i].Query, vars); err != nil {
+			return nil, fmt.Errorf("error in %s query %q: %s", path, queries[i].Name, err)
+		}
+	}
+
+	return queries, nil
+}
+
+// splitQueries splits text into queries at each semicolon outside of a
+// quoted string.  Line comments, starting with -- or //, are removed, and
+// the last line of the comment directly above a query names it.
+func splitQueries(text string) []fileQuery {
+	queries := []fileQuery{}
+
+	var current strings.Builder
+	var comment string
+	var quote rune
+
+	flush := func() {
+		q := strings.TrimSpace(current.String())
+		current.Reset()
+
+		if q == "" {
+			return
+		}
+
+		name := comment
+		if name == "" {
+			name = fmt.Sprintf("query %d", len(queries)+1)
+		}
+
+		queries = append(queries, fileQuery{Name: name, Query: q})
+		comment = ""
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if quote == 0 {
+			// A blank line detaches a comment from the query below it
+			if trimmed == "" && strings.TrimSpace(current.String()) == "" {
+				comment = ""
+			}
+
+			if strings.HasPrefix(trimmed, "--") || strings.HasPrefix(trimmed, "//") {
+				if strings.TrimSpace(current.String()) == "" {
+					comment = strings.TrimSpace(trimmed[2:])
+				}
+				continue
+			}
+		}
+
+		runes := []rune(line)
+		for i := 0; i < len(runes); i++ {
+			r := runes[i]
+
+			switch {
+			case quote != 0:
+				if r == quote {
+					quote = 0
+				}
+			case r == '\'' || r == '"' || r == '`':
+				quote = r
+			case r == ';':
+				flush()
+				continue
+			case isCommentStart(runes[i:]):
+				// Drop the rest of the line
+				i = len(runes)
+				continue
+			}
+
+			current.WriteRune(r)
+		}
+
+		current.WriteRune('\n')
+	}
+
+	flush()
+
+	// Tidy the lines of each query
+	for i, q := range queries {
+		lines := []string{}
+		for _, l := range strings.Split(q.Query, "\n") {
+			if l = strings.TrimSpace(l); l != "" {
+				lines = append(lines, l)
+			}
+		}
+
+		queries[i].Query = strings.Join(lines, " ")
+	}
+
+	return queries
+}
+
+func isCommentStart(runes []rune) bool {
+	return len(runes) > 1 && (runes[0] == '-' && runes[1] == '-' || runes[0] == '/' && runes[1] == '/')
+}
+
+// renderQuery fills in the {{.name}} parameters of a query.  A parameter
+// without a value is an error.
+func renderQuery(query string, vars map[string]interface{}) (string, error) {
+	tmpl, err := template.New("query").Option("missingkey=error").Parse(query)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// expandQuery renders the query given with --query.  It is only treated as
+// a template when variables are given, so NRQL containing {{ still works.
+func expandQuery(query string, vars map[string]interface{}) (string, error) {
+	if vars == nil {
+		return query, nil
+	}
+
+	return renderQuery(query, vars)
+}
+
+// queryVars returns the values of query parameters, read from a YAML or JSON
+// file and then from key=value pairs, which take precedence.  Nil is returned
+// when there are none.
+func queryVars(path string, pairs []string) (map[string]interface{}, error) {
+	if path == "" && len(pairs) == 0 {
+		return nil, nil
+	}
+
+	vars := map[string]interface{}{}
+
+	if path != "" {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = yaml.Unmarshal(content, &vars); err != nil {
+			return nil, fmt.Errorf("error reading variables from %s: %s", path, err)
+		}
+	}
+
+	for _, pair := range pairs {
+		v := strings.SplitN(pair, "=", 2)
+		if len(v) != 2 || v[0] == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected key=value", pair)
+		}
+
+		vars[v[0]] = v[1]
+	}
+
+	return vars, nil
+}
@@ -0,0 +1,126 @@
+// +build unit
+
+package nrql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+func TestSplitQueries(t *testing.T) {
+	text := `// A header, which isn't a name
+
+-- Errors
+SELECT count(*) FROM TransactionError
+  WHERE appName = 'a;b' -- don't split here; or here
+  FACET host;
+SELECT 1 FROM Transaction; SELECT "x--y" FROM Log
+-- Trailing comment`
+
+	assert.Equal(t, []fileQuery{
+		{Name: "Errors", Query: "SELECT count(*) FROM TransactionError WHERE appName = 'a;b' FACET host"},
+		{Name: "query 2", Query: "SELECT 1 FROM Transaction"},
+		{Name: "query 3", Query: `SELECT "x--y" FROM Log`},
+	}, splitQueries(text))
+
+	assert.Empty(t, splitQueries("-- nothing\n;\n"))
+}
+
+func TestRenderQuery(t *testing.T) {
+	vars := map[string]interface{}{"appName": "checkout", "since": "1 hour ago"}
+
+	q, err := renderQuery("SELECT count(*) FROM Transaction WHERE appName = '{{.appName}}' SINCE {{.since}}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT count(*) FROM Transaction WHERE appName = 'checkout' SINCE 1 hour ago", q)
+
+	_, err = renderQuery("SELECT count(*) FROM {{.eventType}}", vars)
+	assert.Error(t, err)
+
+	_, err = renderQuery("SELECT {{", vars)
+	assert.Error(t, err)
+}
+
+func TestExpandQuery(t *testing.T) {
+	// Without variables the query is used as given
+	vars, err := queryVars("", nil)
+	require.NoError(t, err)
+
+	q, err := expandQuery("SELECT count(*) FROM Log WHERE message LIKE '%{{%'", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT count(*) FROM Log WHERE message LIKE '%{{%'", q)
+
+	vars, err = queryVars("", []string{"since=1 hour ago"})
+	require.NoError(t, err)
+
+	q, err = expandQuery("SELECT count(*) FROM Log SINCE {{.since}}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT count(*) FROM Log SINCE 1 hour ago", q)
+}
+
+func TestQueryVars(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-nrql")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "vars.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("appName: checkout\nsince: 1 day ago\n"), 0600))
+
+	vars, err := queryVars(path, []string{"since=1 hour ago", "where=a=b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"appName": "checkout", "since": "1 hour ago", "where": "a=b"}, vars)
+
+	_, err = queryVars("", []string{"novalue"})
+	assert.Error(t, err)
+
+	_, err = queryVars(filepath.Join(dir, "missing.yaml"), nil)
+	assert.Error(t, err)
+}
+
+func TestReadQueryFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-nrql")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "queries.nrql")
+	require.NoError(t, ioutil.WriteFile(path, []byte("-- Count\nSELECT count(*) FROM {{.eventType}};\n"), 0600))
+
+	queries, err := readQueryFile(path, map[string]interface{}{"eventType": "Transaction"})
+	require.NoError(t, err)
+	assert.Equal(t, []fileQuery{{Name: "Count", Query: "SELECT count(*) FROM Transaction"}}, queries)
+
+	_, err = readQueryFile(path, map[string]interface{}{})
+	assert.Error(t, err)
+
+	empty := filepath.Join(dir, "empty.nrql")
+	require.NoError(t, ioutil.WriteFile(empty, []byte("-- nothing here\n"), 0600))
+
+	_, err = readQueryFile(empty, nil)
+	assert.Error(t, err)
+}
+
+func TestRunQueries(t *testing.T) {
+	querier := &fakeQuerier{
+		results: map[string][]nrdb.NRDBResult{
+			"SELECT 1": {{"value": float64(1)}},
+			"SELECT 2": {{"value": float64(2)}},
+		},
+	}
+
+	results := runQueries(&accountsQuerier{querier: querier, accountIDs: []int{1}}, []fileQuery{
+		{Name: "one", Query: "SELECT 1"},
+		{Name: "two", Query: "SELECT 2"},
+	})
+
+	assert.Equal(t, []fileResult{
+		{Name: "one", Query: "SELECT 1", Results: []nrdb.NRDBResult{{"value": float64(1)}}},
+		{Name: "two", Query: "SELECT 2", Results: []nrdb.NRDBResult{{"value": float64(2)}}},
+	}, results)
+	assert.Equal(t, []string{"SELECT 1", "SELECT 2"}, querier.queries)
+}
@@ -0,0 +1,185 @@
+package nrql
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/chzyer/readline"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+const (
+	shellPrompt             = "nrql> "
+	shellContinuationPrompt = "  ... "
+
+	shellHelp = `Enter a NRQL query, ending it with ; or a blank line to run it.
+
+  \account [id]  show the account queried, or switch to another
+  \refresh       fetch the event types and attributes for completion again
+  \help          show this help
+  \quit          leave the shell, as does Ctrl-D
+`
+)
+
+// shell reads NRQL queries and meta-commands, runs the queries and prints
+// their results
+type shell struct {
+	querier   nrqlQuerier
+	accountID int
+	cacheDir  string
+	schema    *schemaCache
+	out       io.Writer
+
+	// The lines of the statement being entered
+	lines []string
+}
+
+func newShell(querier nrqlQuerier, accountID int, cacheDir string, out io.Writer) *shell {
+	s := &shell{
+		querier:  querier,
+		cacheDir: cacheDir,
+		out:      out,
+	}
+
+	s.setAccount(accountID)
+
+	return s
+}
+
+func (s *shell) setAccount(accountID int) {
+	s.accountID = accountID
+	s.schema = loadSchemaCache(s.cacheDir, accountID, s.querier)
+}
+
+// run reads lines until the input ends or \quit is entered
+func (s *shell) run(rl *readline.Instance) error {
+	fmt.Fprintf(s.out, "Querying account %d, enter \\help for help.\n", s.accountID)
+
+	for {
+		line, err := rl.Readline()
+
+		if err == readline.ErrInterrupt {
+			// Abandon the statement being entered
+			s.lines = nil
+			rl.SetPrompt(shellPrompt)
+			continue
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		query, quit := s.handleLine(line)
+		if quit {
+			return nil
+		}
+
+		if query != "" {
+			if err := rl.SaveHistory(query); err != nil {
+				log.Debugf("unable to save NRQL history: %s", err)
+			}
+
+			s.runQuery(query)
+		}
+
+		if len(s.lines) > 0 {
+			rl.SetPrompt(shellContinuationPrompt)
+		} else {
+			rl.SetPrompt(shellPrompt)
+		}
+	}
+}
+
+// handleLine adds a line to the statement being entered, returning the
+// query once it is complete.  Meta-commands are handled here.
+func (s *shell) handleLine(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+
+	if len(s.lines) == 0 {
+		switch {
+		case trimmed == "":
+			return "", false
+		case trimmed == "exit" || trimmed == "quit":
+			return "", true
+		case strings.HasPrefix(trimmed, `\`):
+			return "", s.metaCommand(trimmed)
+		}
+	}
+
+	complete := trimmed == "" || strings.HasSuffix(trimmed, ";")
+	if trimmed != "" {
+		s.lines = append(s.lines, strings.TrimSuffix(trimmed, ";"))
+	}
+
+	if !complete {
+		return "", false
+	}
+
+	query := strings.TrimSpace(strings.Join(s.lines, " "))
+	s.lines = nil
+
+	return query, false
+}
+
+// metaCommand runs a command starting with a backslash, returning true if
+// the shell should exit
+func (s *shell) metaCommand(command string) bool {
+	fields := strings.Fields(command)
+
+	switch fields[0] {
+	case `\q`, `\quit`:
+		return true
+	case `\h`, `\help`, `\?`:
+		fmt.Fprint(s.out, shellHelp)
+	case `\account`:
+		if len(fields) == 1 {
+			fmt.Fprintf(s.out, "Querying account %d\n", s.accountID)
+			break
+		}
+
+		accountID, err := strconv.Atoi(fields[1])
+		if err != nil || accountID <= 0 {
+			log.Errorf("invalid account ID %q", fields[1])
+			break
+		}
+
+		s.setAccount(accountID)
+		fmt.Fprintf(s.out, "Querying account %d\n", s.accountID)
+	case `\refresh`:
+		s.schema.refresh()
+		fmt.Fprintf(s.out, "Found %d event types\n", len(s.schema.eventTypes()))
+	default:
+		log.Errorf("unknown command %s, enter \\help for help", fields[0])
+	}
+
+	return false
+}
+
+// runQuery runs a query and prints its results.  Errors are logged rather
+// than ending the shell.
+func (s *shell) runQuery(query string) {
+	result, err := s.querier.Query(s.accountID, nrdb.NRQL(query))
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	if err = output.Print(result.Results); err != nil {
+		log.Error(err)
+	}
+}
+
+// statement returns the lines of the statement entered so far, for
+// completion
+func (s *shell) statement() string {
+	return strings.Join(s.lines, " ")
+}
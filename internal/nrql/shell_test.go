@@ -0,0 +1,156 @@
+// +build unit
+
+package nrql
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+type fakeQuerier struct {
+	queries []string
+	results map[string][]nrdb.NRDBResult
+}
+
+func (f *fakeQuerier) Query(accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	f.queries = append(f.queries, string(query))
+
+	results, ok := f.results[string(query)]
+	if !ok {
+		return nil, errors.New("unexpected query")
+	}
+
+	return &nrdb.NRDBResultContainer{Results: results}, nil
+}
+
+func TestShellCommand(t *testing.T) {
+	assert.Equal(t, "shell", cmdShell.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdShell)
+	testcobra.CheckCobraRequiredFlags(t, cmdShell, []string{})
+}
+
+func TestShellHandleLine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-nrql")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	s := newShell(&fakeQuerier{}, 1, dir, &out)
+
+	query, quit := s.handleLine("   ")
+	assert.Empty(t, query)
+	assert.False(t, quit)
+
+	// A statement is complete when it ends with a semicolon
+	query, _ = s.handleLine("SELECT count(*)")
+	assert.Empty(t, query)
+	assert.Equal(t, "SELECT count(*)", s.statement())
+
+	query, _ = s.handleLine("  FROM Transaction;")
+	assert.Equal(t, "SELECT count(*) FROM Transaction", query)
+	assert.Empty(t, s.statement())
+
+	// ...or with a blank line
+	query, _ = s.handleLine("SELECT count(*) FROM PageView")
+	assert.Empty(t, query)
+
+	query, _ = s.handleLine("")
+	assert.Equal(t, "SELECT count(*) FROM PageView", query)
+
+	// Meta-commands aren't part of a statement
+	_, quit = s.handleLine(`\account 42`)
+	assert.False(t, quit)
+	assert.Equal(t, 42, s.accountID)
+	assert.Contains(t, out.String(), "Querying account 42")
+
+	_, quit = s.handleLine(`\account nope`)
+	assert.False(t, quit)
+	assert.Equal(t, 42, s.accountID)
+
+	_, quit = s.handleLine(`\help`)
+	assert.False(t, quit)
+	assert.Contains(t, out.String(), `\refresh`)
+
+	_, quit = s.handleLine(`\quit`)
+	assert.True(t, quit)
+
+	_, quit = s.handleLine("exit")
+	assert.True(t, quit)
+}
+
+func TestCompletions(t *testing.T) {
+	candidates := []string{"SELECT", "SINCE", "count", "Transaction", "TransactionError", "name"}
+
+	assert.Equal(t, [][]rune{[]rune("LECT")}, completions("SE", candidates))
+	assert.Equal(t, [][]rune{[]rune("lect")}, completions("se", candidates))
+	assert.Equal(t, [][]rune{[]rune("ansaction"), []rune("ansactionError")}, completions("Tr", candidates))
+	assert.Equal(t, [][]rune{[]rune("me")}, completions("na", candidates))
+	assert.Empty(t, completions("tr", candidates))
+	assert.Empty(t, completions("", candidates))
+	assert.Empty(t, completions("name", candidates))
+
+	assert.Equal(t, "na", currentWord("SELECT count(*) FROM Transaction WHERE na"))
+	assert.Equal(t, "", currentWord("SELECT count(*) "))
+}
+
+func TestSelectedEventTypes(t *testing.T) {
+	assert.Equal(t, []string{"Transaction"}, selectedEventTypes("SELECT count(*) FROM Transaction WHERE "))
+	assert.Equal(t, []string{"Transaction", "PageView"}, selectedEventTypes("select count(*) from Transaction, PageView since 1 day ago"))
+	assert.Empty(t, selectedEventTypes("SELECT count(*) FROM "))
+	assert.Empty(t, selectedEventTypes("SELECT count(*)"))
+}
+
+func TestSchemaCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-nrql")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	querier := &fakeQuerier{
+		results: map[string][]nrdb.NRDBResult{
+			"SHOW EVENT TYPES SINCE 1 week ago": {
+				{"eventType": "Transaction"},
+				{"eventType": "PageView"},
+			},
+			"SELECT keyset() FROM `Transaction` SINCE 1 day ago": {
+				{"allKeys": []interface{}{"name", "duration"}, "numericKeys": []interface{}{"duration"}},
+			},
+		},
+	}
+
+	c := loadSchemaCache(dir, 1, querier)
+	assert.Equal(t, []string{"PageView", "Transaction"}, c.eventTypes())
+	assert.Equal(t, []string{"duration", "name"}, c.attributes("Transaction"))
+
+	// Failures aren't retried, or cached
+	assert.Empty(t, c.attributes("Missing"))
+	assert.Empty(t, c.attributes("Missing"))
+	assert.Len(t, querier.queries, 3)
+
+	// The schema is read from the cache
+	cached := &fakeQuerier{}
+	c = loadSchemaCache(dir, 1, cached)
+	assert.Equal(t, []string{"PageView", "Transaction"}, c.eventTypes())
+	assert.Equal(t, []string{"duration", "name"}, c.attributes("Transaction"))
+	assert.Empty(t, cached.queries)
+	assert.NotContains(t, c.Attributes, "Missing")
+
+	// ...which is kept for each account
+	c = loadSchemaCache(dir, 2, cached)
+	assert.Empty(t, c.eventTypes())
+	assert.Len(t, cached.queries, 1)
+
+	c = loadSchemaCache(dir, 1, querier)
+	c.refresh()
+	c.eventTypes()
+	assert.Len(t, querier.queries, 4)
+}
@@ -0,0 +1,184 @@
+package nrql
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+// minWatchInterval keeps --watch from flooding NerdGraph with queries
+const minWatchInterval = time.Second
+
+var thresholdRegexp = regexp.MustCompile("^\\s*(`[^`]+`|[\\w.]+)\\s*(>=|<=|==|!=|>|<)\\s*(\\S+)\\s*$")
+
+// threshold compares a value of a query's results with a number, such as
+// count > 100
+type threshold struct {
+	field    string
+	operator string
+	value    float64
+}
+
+func parseThreshold(expr string) (*threshold, error) {
+	m := thresholdRegexp.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, fmt.Errorf("invalid threshold %q, expected a comparison such as 'count > 100'", expr)
+	}
+
+	value, err := strconv.ParseFloat(m[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold %q, %s is not a number", expr, m[3])
+	}
+
+	return &threshold{
+		field:    strings.Trim(m[1], "`"),
+		operator: m[2],
+		value:    value,
+	}, nil
+}
+
+func (t *threshold) String() string {
+	return fmt.Sprintf("%s %s %s", t.field, t.operator, strconv.FormatFloat(t.value, 'f', -1, 64))
+}
+
+// check describes the first value of the results which meets the threshold,
+// returning an empty string if none does.  Only the latest bucket of a
+// TIMESERIES query is checked, and the attributes faceted by never are.
+func (t *threshold) check(results []nrdb.NRDBResult, facets []string) (string, error) {
+	found := len(results) == 0
+	fields := map[string]bool{}
+
+	for _, r := range latestBucket(results) {
+		values := chartValues(r, facets)
+		for name := range values {
+			fields[name] = true
+		}
+
+		v, ok := values[t.field]
+		if !ok {
+			continue
+		}
+
+		found = true
+		if !t.compare(v) {
+			continue
+		}
+
+		met := fmt.Sprintf("%s is %s", t.field, strconv.FormatFloat(v, 'f', -1, 64))
+		if facet := facetName(r); facet != "" {
+			met += " for " + facet
+		}
+
+		if accountID, ok := r["accountId"]; ok {
+			met += fmt.Sprintf(" in account %v", accountID)
+		}
+
+		return met, nil
+	}
+
+	if !found {
+		names := []string{}
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return "", fmt.Errorf("threshold field %s is not in the results, which have: %s", t.field, strings.Join(names, ", "))
+	}
+
+	return "", nil
+}
+
+func (t *threshold) compare(v float64) bool {
+	switch t.operator {
+	case ">":
+		return v > t.value
+	case ">=":
+		return v >= t.value
+	case "<":
+		return v < t.value
+	case "<=":
+		return v <= t.value
+	case "==":
+		return v == t.value
+	case "!=":
+		return v != t.value
+	}
+
+	return false
+}
+
+// latestBucket returns the results of the last bucket of a TIMESERIES query,
+// or all the results of any other query
+func latestBucket(results []nrdb.NRDBResult) []nrdb.NRDBResult {
+	if len(results) == 0 {
+		return results
+	}
+
+	if _, ok := results[0]["beginTimeSeconds"]; !ok {
+		return results
+	}
+
+	latest := toFloat(results[0]["beginTimeSeconds"])
+	for _, r := range results {
+		if begin := toFloat(r["beginTimeSeconds"]); begin > latest {
+			latest = begin
+		}
+	}
+
+	bucket := []nrdb.NRDBResult{}
+	for _, r := range results {
+		if toFloat(r["beginTimeSeconds"]) == latest {
+			bucket = append(bucket, r)
+		}
+	}
+
+	return bucket
+}
+
+// checkThreshold exits with an error once the results meet the threshold
+func checkThreshold(limit *threshold, result *nrdb.NRDBResultContainer) {
+	if limit == nil {
+		return
+	}
+
+	met, err := limit.check(result.Results, result.Metadata.Facets)
+	utils.LogIfFatal(err)
+
+	if met != "" {
+		log.Fatalf("threshold %s met: %s", limit, met)
+	}
+}
+
+// watch runs a query every interval, drawing its results in place of the
+// last, until the threshold is met.  Errors are logged rather than ending
+// the watch, so it survives a failed request.
+func watch(querier nrqlQuerier, accountID int, nrql string, interval time.Duration, style *output.ChartStyle, limit *threshold) {
+	for {
+		result, err := querier.Query(accountID, nrdb.NRQL(nrql))
+
+		utils.LogIfError(output.ClearScreen())
+		output.Printf("Every %s: %s  (%s)\n", interval, nrql, time.Now().Format("15:04:05"))
+
+		if err != nil {
+			log.Error(err)
+		} else {
+			if err = printResults(result, style); err != nil {
+				log.Error(err)
+			}
+
+			checkThreshold(limit, result)
+		}
+
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,86 @@
+// +build unit
+
+package nrql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+func TestParseThreshold(t *testing.T) {
+	limit, err := parseThreshold("count > 100")
+	require.NoError(t, err)
+	assert.Equal(t, &threshold{field: "count", operator: ">", value: 100}, limit)
+	assert.Equal(t, "count > 100", limit.String())
+
+	limit, err = parseThreshold("`percentile.duration.95`<=0.5")
+	require.NoError(t, err)
+	assert.Equal(t, &threshold{field: "percentile.duration.95", operator: "<=", value: 0.5}, limit)
+
+	for _, expr := range []string{"", "count", "count > ", "count ~ 1", "count > many"} {
+		_, err = parseThreshold(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestThresholdCheck(t *testing.T) {
+	limit := &threshold{field: "count", operator: ">=", value: 10}
+
+	met, err := limit.check([]nrdb.NRDBResult{{"count": float64(9)}}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, met)
+
+	met, err = limit.check([]nrdb.NRDBResult{
+		{"facet": "web", "count": float64(3)},
+		{"facet": "worker", "count": float64(10)},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "count is 10 for worker", met)
+
+	met, err = limit.check([]nrdb.NRDBResult{{"accountId": 42, "count": float64(10)}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "count is 10 in account 42", met)
+
+	// Only the latest bucket of a timeseries is checked
+	timeseries := []nrdb.NRDBResult{
+		{"beginTimeSeconds": float64(0), "endTimeSeconds": float64(60), "count": float64(50)},
+		{"beginTimeSeconds": float64(60), "endTimeSeconds": float64(120), "count": float64(5)},
+	}
+	met, err = limit.check(timeseries, nil)
+	require.NoError(t, err)
+	assert.Empty(t, met)
+
+	met, err = limit.check(nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, met)
+
+	_, err = (&threshold{field: "missing", operator: ">", value: 0}).check([]nrdb.NRDBResult{{"count": float64(1)}}, nil)
+	assert.Error(t, err)
+
+	// A value equal to its facet is still checked
+	met, err = (&threshold{field: "count", operator: ">=", value: 200}).check([]nrdb.NRDBResult{
+		{"facet": "200", "httpResponseCode": "200", "count": float64(200)},
+	}, []string{"httpResponseCode"})
+	require.NoError(t, err)
+	assert.Equal(t, "count is 200 for 200", met)
+}
+
+func TestThresholdCompare(t *testing.T) {
+	cases := map[string][]bool{
+		">":  {false, false, true},
+		">=": {false, true, true},
+		"<":  {true, false, false},
+		"<=": {true, true, false},
+		"==": {false, true, false},
+		"!=": {true, false, true},
+	}
+
+	for operator, expected := range cases {
+		limit := &threshold{field: "count", operator: operator, value: 1}
+		assert.Equal(t, expected, []bool{limit.compare(0), limit.compare(1), limit.compare(2)}, operator)
+	}
+}
@@ -0,0 +1,371 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ChartStyle is the kind of chart drawn by Chart
+type ChartStyle uint
+
+const (
+	ChartLine ChartStyle = iota
+	ChartBar
+	ChartSparkline
+)
+
+// chartHeight is the number of rows of a line chart
+const chartHeight = 10
+
+var chartStyleStrings = map[ChartStyle]string{
+	ChartLine:      "line",
+	ChartBar:       "bar",
+	ChartSparkline: "sparkline",
+}
+
+var (
+	// chartMarkers tell the series of a line chart apart
+	chartMarkers = []rune("*+ox#@%&")
+
+	sparkRunes = []rune("▁▂▃▄▅▆▇█")
+)
+
+// Series is a named list of values to chart, such as the buckets of a
+// timeseries for one facet.  Each value has a label, such as the time of
+// its bucket.
+type Series struct {
+	Name   string
+	Labels []string
+	Values []float64
+}
+
+// String returns the name of the chart style
+func (s ChartStyle) String() string {
+	return chartStyleStrings[s]
+}
+
+// ChartStyleOptions returns the names of the chart styles, for help text
+func ChartStyleOptions() string {
+	ret := make([]string, 0, len(chartStyleStrings))
+
+	for s := ChartStyle(0); int(s) < len(chartStyleStrings); s++ {
+		ret = append(ret, s.String())
+	}
+
+	return strings.Join(ret, ", ")
+}
+
+// ParseChartStyle returns the chart style with the given name
+func ParseChartStyle(name string) (ChartStyle, error) {
+	for k, v := range chartStyleStrings {
+		if strings.EqualFold(name, v) {
+			return k, nil
+		}
+	}
+
+	return ChartLine, fmt.Errorf("unknown chart style %q, must be one of: %s", name, ChartStyleOptions())
+}
+
+// Chart draws the series as a chart sized to the width of the terminal,
+// followed by the minimum, maximum and average of each series
+func Chart(series []Series, style ChartStyle) error {
+	if err := ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	return globalOutput.chart(series, style)
+}
+
+func (o *Output) chart(series []Series, style ChartStyle) error {
+	if o == nil {
+		return errors.New("invalid output formatter")
+	}
+
+	if len(series) == 0 {
+		return errors.New("nothing to chart")
+	}
+
+	switch style {
+	case ChartBar:
+		o.barChart(series)
+	case ChartSparkline:
+		o.sparklines(series)
+	default:
+		o.lineChart(series)
+	}
+
+	return nil
+}
+
+// lineChart plots every series on the same axes, using a marker for each
+func (o *Output) lineChart(series []Series) {
+	min, max := seriesRange(series)
+
+	top := formatChartValue(max)
+	bottom := formatChartValue(min)
+	axisWidth := maxLength([]string{top, bottom})
+
+	width := o.terminalWidth - axisWidth - 3
+	if width < 1 {
+		width = 1
+	}
+
+	grid := make([][]rune, chartHeight)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", width))
+	}
+
+	columns := 0
+
+	for i, s := range series {
+		values := stretch(resample(s.Values, width), width)
+		if len(values) > columns {
+			columns = len(values)
+		}
+
+		for x, v := range values {
+			row := scale(v, min, max, chartHeight-1)
+			grid[chartHeight-1-row][x] = chartMarkers[i%len(chartMarkers)]
+		}
+	}
+
+	for i, row := range grid {
+		label := ""
+		switch i {
+		case 0:
+			label = top
+		case chartHeight - 1:
+			label = bottom
+		}
+
+		fmt.Fprintf(o.writer, "%*s |%s\n", axisWidth, label, strings.TrimRight(string(row), " "))
+	}
+
+	fmt.Fprintf(o.writer, "%*s +%s\n", axisWidth, "", strings.Repeat("-", columns))
+
+	// Label the start and end of the x axis
+	if labels := series[0].Labels; len(labels) > 0 {
+		first := labels[0]
+		last := labels[len(labels)-1]
+
+		gap := columns - utf8.RuneCountInString(first) - utf8.RuneCountInString(last)
+		if gap < 1 {
+			gap = 1
+		}
+
+		fmt.Fprintf(o.writer, "%*s  %s%s%s\n", axisWidth, "", first, strings.Repeat(" ", gap), last)
+	}
+
+	fmt.Fprintln(o.writer)
+
+	names := seriesNames(series)
+	for i, s := range series {
+		fmt.Fprintf(o.writer, "%c %-*s  %s\n", chartMarkers[i%len(chartMarkers)], maxLength(names), s.Name, seriesStats(s.Values))
+	}
+}
+
+// barChart draws a horizontal bar for each value, grouped by series
+func (o *Output) barChart(series []Series) {
+	_, max := seriesRange(series)
+	if max < 0 {
+		max = 0
+	}
+
+	labels := []string{}
+	values := []string{}
+
+	for _, s := range series {
+		labels = append(labels, s.Labels...)
+		for _, v := range s.Values {
+			values = append(values, formatChartValue(v))
+		}
+	}
+
+	labelWidth := maxLength(labels)
+	valueWidth := maxLength(values)
+
+	width := o.terminalWidth - labelWidth - valueWidth - 4
+	if width < 1 {
+		width = 1
+	}
+
+	for i, s := range series {
+		if i > 0 {
+			fmt.Fprintln(o.writer)
+		}
+
+		fmt.Fprintf(o.writer, "%s  %s\n", s.Name, seriesStats(s.Values))
+
+		for j, v := range s.Values {
+			label := ""
+			if j < len(s.Labels) {
+				label = s.Labels[j]
+			}
+
+			bar := strings.Repeat("█", scale(math.Max(v, 0), 0, max, width))
+			fmt.Fprintf(o.writer, "%-*s | %s %s\n", labelWidth, label, bar, formatChartValue(v))
+		}
+	}
+}
+
+// sparklines draws each series on a single line
+func (o *Output) sparklines(series []Series) {
+	names := seriesNames(series)
+	nameWidth := maxLength(names)
+
+	stats := []string{}
+	for _, s := range series {
+		stats = append(stats, seriesStats(s.Values))
+	}
+
+	width := o.terminalWidth - nameWidth - maxLength(stats) - 4
+	if width < 1 {
+		width = 1
+	}
+
+	for i, s := range series {
+		min, max := seriesRange(series[i : i+1])
+
+		var line strings.Builder
+		for _, v := range resample(s.Values, width) {
+			line.WriteRune(sparkRunes[scale(v, min, max, len(sparkRunes)-1)])
+		}
+
+		fmt.Fprintf(o.writer, "%-*s  %s  %s\n", nameWidth, s.Name, line.String(), stats[i])
+	}
+}
+
+// resample averages the values into at most width values
+func resample(values []float64, width int) []float64 {
+	if len(values) <= width {
+		return values
+	}
+
+	resampled := make([]float64, width)
+
+	for i := range resampled {
+		start := i * len(values) / width
+		end := (i + 1) * len(values) / width
+
+		sum := 0.0
+		for _, v := range values[start:end] {
+			sum += v
+		}
+
+		resampled[i] = sum / float64(end-start)
+	}
+
+	return resampled
+}
+
+// stretch interpolates between the values to fill width columns
+func stretch(values []float64, width int) []float64 {
+	if len(values) < 2 || len(values) >= width {
+		return values
+	}
+
+	stretched := make([]float64, width)
+
+	for i := range stretched {
+		pos := float64(i) * float64(len(values)-1) / float64(width-1)
+		left := int(pos)
+		if left >= len(values)-1 {
+			stretched[i] = values[len(values)-1]
+			continue
+		}
+
+		stretched[i] = values[left] + (values[left+1]-values[left])*(pos-float64(left))
+	}
+
+	return stretched
+}
+
+// scale maps a value between min and max onto 0 to steps
+func scale(v float64, min float64, max float64, steps int) int {
+	if max <= min {
+		return 0
+	}
+
+	s := int(math.Round((v - min) / (max - min) * float64(steps)))
+
+	switch {
+	case s < 0:
+		return 0
+	case s > steps:
+		return steps
+	}
+
+	return s
+}
+
+func seriesRange(series []Series) (float64, float64) {
+	min := math.Inf(1)
+	max := math.Inf(-1)
+
+	for _, s := range series {
+		for _, v := range s.Values {
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+	}
+
+	if math.IsInf(min, 1) {
+		return 0, 0
+	}
+
+	return min, max
+}
+
+// seriesStats returns the minimum, maximum and average of the values
+func seriesStats(values []float64) string {
+	if len(values) == 0 {
+		return "no data"
+	}
+
+	min, max := seriesRange([]Series{{Values: values}})
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+
+	return fmt.Sprintf("min %s  max %s  avg %s",
+		formatChartValue(min), formatChartValue(max), formatChartValue(sum/float64(len(values))))
+}
+
+func seriesNames(series []Series) []string {
+	names := make([]string, len(series))
+	for i, s := range series {
+		names[i] = s.Name
+	}
+
+	return names
+}
+
+// formatChartValue prints a value with at most two decimal places
+func formatChartValue(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+
+	if s == "-0" {
+		return "0"
+	}
+
+	return s
+}
+
+func maxLength(values []string) int {
+	max := 0
+
+	for _, v := range values {
+		if l := utf8.RuneCountInString(v); l > max {
+			max = l
+		}
+	}
+
+	return max
+}
@@ -0,0 +1,70 @@
+// +build unit
+
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChartStyle(t *testing.T) {
+	t.Parallel()
+
+	style, err := ParseChartStyle("Sparkline")
+	require.NoError(t, err)
+	assert.Equal(t, ChartSparkline, style)
+
+	_, err = ParseChartStyle("pie")
+	assert.Error(t, err)
+
+	assert.Equal(t, "line, bar, sparkline", ChartStyleOptions())
+}
+
+func TestChart(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	o := &Output{terminalWidth: 40, writer: &buf}
+
+	series := []Series{
+		{Name: "web", Labels: []string{"10:00", "10:01", "10:02"}, Values: []float64{1, 2, 3}},
+		{Name: "worker", Labels: []string{"10:00", "10:01", "10:02"}, Values: []float64{4, 0.5, 2}},
+	}
+
+	require.NoError(t, o.chart(series, ChartSparkline))
+	assert.Equal(t, "web     ▁▅█  min 1  max 3  avg 2\nworker  █▁▄  min 0.5  max 4  avg 2.17\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, o.chart(series[:1], ChartBar))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "web  min 1  max 3  avg 2", lines[0])
+	assert.True(t, strings.HasPrefix(lines[3], "10:02 | █"))
+	assert.True(t, strings.HasSuffix(lines[3], " 3"))
+	assert.Equal(t, 40, len([]rune(lines[3])))
+
+	buf.Reset()
+	require.NoError(t, o.chart(series, ChartLine))
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, chartHeight+5)
+	assert.True(t, strings.HasPrefix(lines[0], "4 |"))
+	assert.True(t, strings.HasPrefix(lines[chartHeight-1], "0.5 |"))
+	assert.Contains(t, lines[chartHeight+1], "10:00")
+	assert.Contains(t, lines[chartHeight+1], "10:02")
+	assert.Equal(t, "+ worker  min 0.5  max 4  avg 2.17", lines[chartHeight+4])
+
+	assert.Error(t, o.chart(nil, ChartLine))
+}
+
+func TestResample(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []float64{1.5, 3.5}, resample([]float64{1, 2, 3, 4}, 2))
+	assert.Equal(t, []float64{1, 2}, resample([]float64{1, 2}, 5))
+	assert.Equal(t, []float64{0, 1, 2, 3, 4}, stretch([]float64{0, 4}, 5))
+	assert.Equal(t, []float64{7}, stretch([]float64{7}, 5))
+}
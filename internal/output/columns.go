@@ -0,0 +1,122 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SetColumns limits tabular output (Text, CSV and TSV) to the given
+// columns, in the order provided.  Selecting a nested object such as
+// "account" includes all of its flattened fields.
+func SetColumns(columns []string) (err error) {
+	if err = ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	globalOutput.columns = columns
+
+	return nil
+}
+
+// SetSortBy sorts the rows of tabular output by the given column.  The
+// sort is descending when the column name is prefixed with "-".
+func SetSortBy(column string) (err error) {
+	if err = ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	globalOutput.sortBy = column
+
+	return nil
+}
+
+// selectColumns resolves the configured columns against the available header
+func (o *Output) selectColumns(header []string) ([]string, error) {
+	if len(o.columns) == 0 {
+		return header, nil
+	}
+
+	selected := []string{}
+
+	for _, c := range o.columns {
+		matched := matchColumns(strings.TrimSpace(c), header)
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("unknown column %q, available columns are: %s", c, strings.Join(header, ", "))
+		}
+
+		selected = append(selected, matched...)
+	}
+
+	return selected, nil
+}
+
+// sortRecords sorts records in place by the configured sort column,
+// comparing numerically when both values are numbers.
+func (o *Output) sortRecords(header []string, records []record) error {
+	if o.sortBy == "" {
+		return nil
+	}
+
+	column := o.sortBy
+	descending := strings.HasPrefix(column, "-")
+	if descending {
+		column = column[1:]
+	}
+
+	matched := matchColumns(column, header)
+	if len(matched) != 1 {
+		return fmt.Errorf("unknown sort column %q, available columns are: %s", column, strings.Join(header, ", "))
+	}
+
+	key := matched[0]
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if descending {
+			return lessValue(records[j][key], records[i][key])
+		}
+
+		return lessValue(records[i][key], records[j][key])
+	})
+
+	return nil
+}
+
+// matchColumns finds the header entries for a column name, preferring an
+// exact match, then a case insensitive one, then all nested fields.
+func matchColumns(name string, header []string) []string {
+	for _, h := range header {
+		if h == name {
+			return []string{h}
+		}
+	}
+
+	for _, h := range header {
+		if strings.EqualFold(h, name) {
+			return []string{h}
+		}
+	}
+
+	matched := []string{}
+	prefix := strings.ToLower(name) + "."
+
+	for _, h := range header {
+		if strings.HasPrefix(strings.ToLower(h), prefix) {
+			matched = append(matched, h)
+		}
+	}
+
+	return matched
+}
+
+func lessValue(a string, b string) bool {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+
+	if errA == nil && errB == nil {
+		return fa < fb
+	}
+
+	return strings.ToLower(a) < strings.ToLower(b)
+}
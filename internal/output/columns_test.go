@@ -0,0 +1,61 @@
+// +build unit
+
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectColumns(t *testing.T) {
+	t.Parallel()
+
+	header := []string{"name", "guid", "account.id", "account.name"}
+
+	o := &Output{}
+	selected, err := o.selectColumns(header)
+	require.NoError(t, err)
+	assert.Equal(t, header, selected)
+
+	o = &Output{columns: []string{"GUID", "account", "name"}}
+	selected, err = o.selectColumns(header)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"guid", "account.id", "account.name", "name"}, selected)
+
+	o = &Output{columns: []string{"missing"}}
+	_, err = o.selectColumns(header)
+	assert.Error(t, err)
+}
+
+func TestSortRecords(t *testing.T) {
+	t.Parallel()
+
+	header := []string{"name", "count"}
+	records := []record{
+		{"name": "b", "count": "10"},
+		{"name": "a", "count": "9"},
+		{"name": "C", "count": "100"},
+	}
+
+	o := &Output{sortBy: "count"}
+	require.NoError(t, o.sortRecords(header, records))
+	assert.Equal(t, []string{"a", "b", "C"}, names(records))
+
+	o = &Output{sortBy: "-name"}
+	require.NoError(t, o.sortRecords(header, records))
+	assert.Equal(t, []string{"C", "b", "a"}, names(records))
+
+	o = &Output{sortBy: "missing"}
+	assert.Error(t, o.sortRecords(header, records))
+}
+
+func names(records []record) []string {
+	n := make([]string, len(records))
+	for i, r := range records {
+		n[i] = r["name"]
+	}
+
+	return n
+}
@@ -1,6 +1,8 @@
 package output
 
 import (
+	"os"
+
 	"github.com/hokaccha/go-prettyjson"
 	"golang.org/x/term"
 )
@@ -11,6 +13,7 @@ func New(opts ...ConfigOption) (*Output, error) {
 		format:        DefaultFormat,
 		prettyPrint:   DefaultPretty,
 		terminalWidth: DefaultTerminalWidth,
+		writer:        os.Stdout,
 	}
 
 	// Set some defaults
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"encoding/csv"
+	"errors"
+)
+
+// csv prints out data as comma separated values
+func (o *Output) csv(data interface{}) error {
+	return o.delimited(data, ',')
+}
+
+// tsv prints out data as tab separated values
+func (o *Output) tsv(data interface{}) error {
+	return o.delimited(data, '\t')
+}
+
+// delimited flattens data into one row per item, with a header row
+// made of the dot-separated paths to each nested value.
+func (o *Output) delimited(data interface{}, comma rune) error {
+	// Early quit on no data
+	if data == nil {
+		return nil
+	}
+
+	if o == nil {
+		return errors.New("invalid output formatter")
+	}
+
+	header, records, err := flattenRecords(data, true)
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err = o.sortRecords(header, records); err != nil {
+		return err
+	}
+
+	if header, err = o.selectColumns(header); err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(o.writer)
+	w.Comma = comma
+
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		row := make([]string, len(header))
+		for i, h := range header {
+			row[i] = rec[h]
+		}
+
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
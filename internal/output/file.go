@@ -0,0 +1,133 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+// StdoutPath is the output file path which refers to standard output
+const StdoutPath = "-"
+
+// outputFile is a file receiving formatted output in place of stdout
+type outputFile struct {
+	path string
+	file *os.File
+
+	// atomic is set when output is written to a temporary file which
+	// replaces path once the command completes successfully
+	atomic bool
+}
+
+// SetOutputFile sends formatted output to the given file rather than
+// stdout.  Output is written to a temporary file in the same directory
+// which atomically replaces the target file when Flush is called, so a
+// failed command never leaves a partially written file behind.  When
+// appendOutput is set, NDJSON output is appended to the file instead.
+func SetOutputFile(path string, appendOutput bool) (err error) {
+	if err = ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	if path == "" || path == StdoutPath {
+		if appendOutput {
+			return errors.New("appending requires an output file")
+		}
+
+		return nil
+	}
+
+	if globalOutput.outputFile != nil {
+		return fmt.Errorf("output file already set to %s", globalOutput.outputFile.path)
+	}
+
+	out := &outputFile{path: path}
+
+	if appendOutput {
+		if globalOutput.format != FormatNDJSON {
+			return fmt.Errorf("appending to an output file is only supported with the %s format", FormatNDJSON)
+		}
+
+		out.file, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("error opening output file %s: %s", path, err)
+		}
+	} else {
+		out.atomic = true
+		out.file, err = ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+		if err != nil {
+			return fmt.Errorf("error creating output file for %s: %s", path, err)
+		}
+
+		// Make sure we don't leave the temporary file behind on log.Fatal
+		log.RegisterExitHandler(Discard)
+	}
+
+	globalOutput.outputFile = out
+	globalOutput.writer = out.file
+	globalOutput.noColor = true
+
+	return nil
+}
+
+// Flush completes any writes to the output file, moving the output into
+// place when it was written to a temporary file.
+func Flush() error {
+	if globalOutput == nil || globalOutput.outputFile == nil {
+		return nil
+	}
+
+	out := globalOutput.outputFile
+	globalOutput.outputFile = nil
+	globalOutput.writer = os.Stdout
+
+	if err := out.file.Close(); err != nil {
+		return fmt.Errorf("error writing output file %s: %s", out.path, err)
+	}
+
+	if !out.atomic {
+		return nil
+	}
+
+	// Keep the permissions of the file we are replacing
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(out.path); err == nil {
+		mode = info.Mode()
+	}
+
+	if err := os.Chmod(out.file.Name(), mode); err != nil {
+		return err
+	}
+
+	if err := os.Rename(out.file.Name(), out.path); err != nil {
+		return fmt.Errorf("error replacing output file %s: %s", out.path, err)
+	}
+
+	log.Debugf("output written to %s", out.path)
+
+	return nil
+}
+
+// Discard abandons any output written to a temporary output file,
+// leaving the target file untouched.
+func Discard() {
+	if globalOutput == nil || globalOutput.outputFile == nil {
+		return
+	}
+
+	out := globalOutput.outputFile
+	globalOutput.outputFile = nil
+	globalOutput.writer = os.Stdout
+
+	utils.LogIfError(out.file.Close())
+
+	if out.atomic {
+		utils.LogIfError(os.Remove(out.file.Name()))
+	}
+}
@@ -0,0 +1,65 @@
+// +build unit
+
+package output
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-output")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "result.ndjson")
+	require.NoError(t, ioutil.WriteFile(path, []byte("previous\n"), 0600))
+
+	require.NoError(t, SetFormat(FormatNDJSON))
+	defer SetFormat(DefaultFormat) // nolint:errcheck
+
+	// Nothing is replaced until the output is flushed
+	require.NoError(t, SetOutputFile(path, false))
+	require.NoError(t, Print([]map[string]int{{"a": 1}, {"a": 2}}))
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "previous\n", string(content))
+
+	require.NoError(t, Flush())
+
+	content, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n", string(content))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Appending adds to the existing file
+	require.NoError(t, SetOutputFile(path, true))
+	require.NoError(t, Print([]map[string]int{{"a": 3}}))
+	require.NoError(t, Flush())
+
+	content, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n{\"a\":3}\n", string(content))
+
+	// Discarded output leaves no trace
+	require.NoError(t, SetOutputFile(path, false))
+	require.NoError(t, Print([]map[string]int{{"a": 4}}))
+	Discard()
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// Appending is only supported for NDJSON
+	require.NoError(t, SetFormat(FormatJSON))
+	assert.Error(t, SetOutputFile(path, true))
+}
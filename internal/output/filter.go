@@ -0,0 +1,96 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"gopkg.in/yaml.v2"
+)
+
+// SetFilter sets the GJSON path expression applied to every result
+// before it is handed to the formatters.  An empty expression disables
+// filtering.  See https://github.com/tidwall/gjson/blob/master/SYNTAX.md
+func SetFilter(expr string) (err error) {
+	if err = ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	globalOutput.filter = expr
+
+	return nil
+}
+
+// applyFilter runs the configured filter expression against the JSON
+// representation of data.  Plain strings are messages rather than
+// results and are passed through untouched.
+func (o *Output) applyFilter(data interface{}) (interface{}, error) {
+	if o == nil {
+		return nil, errors.New("invalid output formatter")
+	}
+
+	if o.filter == "" || data == nil {
+		return data, nil
+	}
+
+	if _, ok := data.(string); ok {
+		return data, nil
+	}
+
+	raw, err := toJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := gjson.GetBytes(raw, o.filter)
+	if !result.Exists() {
+		log.Debugf("filter %q did not match any data", o.filter)
+		return nil, nil
+	}
+
+	return normalize([]byte(result.Raw))
+}
+
+// MarshalJSON writes the object keys in their original order.
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML writes the object keys in their original order.
+func (m *orderedMap) MarshalYAML() (interface{}, error) {
+	slice := make(yaml.MapSlice, 0, len(m.keys))
+
+	for _, k := range m.keys {
+		slice = append(slice, yaml.MapItem{Key: k, Value: m.values[k]})
+	}
+
+	return slice, nil
+}
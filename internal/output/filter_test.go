@@ -0,0 +1,67 @@
+// +build unit
+
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+type filterTestEntity struct {
+	Name    string `json:"name"`
+	GUID    string `json:"guid"`
+	Account struct {
+		ID int `json:"id"`
+	} `json:"account"`
+}
+
+func TestApplyFilter(t *testing.T) {
+	t.Parallel()
+
+	e1 := filterTestEntity{Name: "one", GUID: "abc"}
+	e1.Account.ID = 1
+	e2 := filterTestEntity{Name: "two", GUID: "def"}
+	e2.Account.ID = 2
+
+	data := []filterTestEntity{e1, e2}
+
+	o := &Output{filter: "#.name"}
+	result, err := o.applyFilter(data)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"one", "two"}, result)
+
+	o = &Output{filter: "#(guid==\"def\").account.id"}
+	result, err = o.applyFilter(data)
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("2"), result)
+
+	o = &Output{filter: "missing"}
+	result, err = o.applyFilter(data)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	// Messages are never filtered
+	result, err = o.applyFilter("a message")
+	require.NoError(t, err)
+	assert.Equal(t, "a message", result)
+}
+
+func TestApplyFilterKeepsFieldOrder(t *testing.T) {
+	t.Parallel()
+
+	o := &Output{filter: "@this"}
+	result, err := o.applyFilter([]byte(`{"zeta": 1, "alpha": {"b": true, "a": null}}`))
+	require.NoError(t, err)
+
+	j, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Equal(t, `{"zeta":1,"alpha":{"b":true,"a":null}}`, string(j))
+
+	y, err := yaml.Marshal(result)
+	require.NoError(t, err)
+	assert.Equal(t, "zeta: 1\nalpha:\n  b: true\n  a: null\n", string(y))
+}
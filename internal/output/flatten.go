@@ -0,0 +1,250 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// orderedMap is a JSON object which remembers the order its keys were
+// decoded in, so flattened output follows struct field order.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// record is a single flattened result item, keyed by dot-separated paths
+// built from the JSON field names of the original value.
+type record map[string]string
+
+// normalize converts any value into its generic JSON representation,
+// using *orderedMap for objects and json.Number for numbers.
+func normalize(data interface{}) (interface{}, error) {
+	raw, err := toJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	return decodeOrdered(dec)
+}
+
+// toJSON returns the JSON encoding of data, passing through values
+// which are already raw JSON.
+func toJSON(data interface{}) ([]byte, error) {
+	switch d := data.(type) {
+	case *bytes.Buffer:
+		return d.Bytes(), nil
+	case []byte:
+		return d, nil
+	case json.RawMessage:
+		return d, nil
+	}
+
+	return json.Marshal(data)
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		m := &orderedMap{values: map[string]interface{}{}}
+
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			key := keyTok.(string)
+
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, exists := m.values[key]; !exists {
+				m.keys = append(m.keys, key)
+			}
+			m.values[key] = value
+		}
+
+		// Consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return m, nil
+	case '[':
+		arr := []interface{}{}
+
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, value)
+		}
+
+		// Consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return arr, nil
+	}
+
+	return nil, fmt.Errorf("unexpected JSON delimiter: %s", delim)
+}
+
+// flattenRecords turns data into a list of flat records, one per element
+// when data is a list, along with the union of their keys in the order
+// they were first seen.  Arrays nested within an item are expanded into
+// indexed paths when expandArrays is set, and summarized otherwise.
+func flattenRecords(data interface{}, expandArrays bool) ([]string, []record, error) {
+	normalized, err := normalize(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header, records := recordsFrom(normalized, expandArrays)
+
+	return header, records, nil
+}
+
+// recordsFrom flattens an already normalized value, see flattenRecords.
+func recordsFrom(normalized interface{}, expandArrays bool) ([]string, []record) {
+	var items []interface{}
+
+	switch n := normalized.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		items = n
+	default:
+		items = []interface{}{n}
+	}
+
+	header := []string{}
+	seen := map[string]bool{}
+	records := make([]record, 0, len(items))
+
+	for _, item := range items {
+		rec := record{}
+		keys := []string{}
+
+		flatten("", item, rec, &keys, expandArrays)
+
+		for _, k := range keys {
+			if !seen[k] {
+				seen[k] = true
+				header = append(header, k)
+			}
+		}
+
+		records = append(records, rec)
+	}
+
+	return header, records
+}
+
+// flatten walks a normalized value, storing each scalar under its dot path.
+func flatten(prefix string, value interface{}, rec record, keys *[]string, expandArrays bool) {
+	switch v := value.(type) {
+	case *orderedMap:
+		for _, k := range v.keys {
+			flatten(joinPath(prefix, k), v.values[k], rec, keys, expandArrays)
+		}
+		return
+	case []interface{}:
+		if expandArrays {
+			for i, elem := range v {
+				flatten(joinPath(prefix, strconv.Itoa(i)), elem, rec, keys, expandArrays)
+			}
+			return
+		}
+
+		value = summarize(v)
+	}
+
+	if prefix == "" {
+		prefix = "value"
+	}
+
+	if _, exists := rec[prefix]; !exists {
+		*keys = append(*keys, prefix)
+	}
+	rec[prefix] = scalarString(value)
+}
+
+// summarize collapses a nested array into a single value, joining lists
+// of scalars and eliding anything more complex.
+func summarize(values []interface{}) string {
+	parts := make([]string, len(values))
+
+	if !isScalarList(values) {
+		return fmt.Sprintf("[%d items]", len(values))
+	}
+
+	for i, v := range values {
+		parts[i] = scalarString(v)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func isScalarList(values []interface{}) bool {
+	for _, v := range values {
+		if !isScalar(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case *orderedMap, []interface{}:
+		return false
+	}
+
+	return true
+}
+
+func joinPath(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return strings.Join([]string{prefix, key}, ".")
+}
+
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
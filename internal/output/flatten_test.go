@@ -0,0 +1,103 @@
+// +build unit
+
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenRecordsStructs(t *testing.T) {
+	t.Parallel()
+
+	type tag struct {
+		Key    string   `json:"key"`
+		Values []string `json:"values"`
+	}
+
+	type entity struct {
+		Name    string `json:"name"`
+		GUID    string `json:"guid"`
+		Account struct {
+			ID int `json:"id"`
+		} `json:"account"`
+		Tags   []tag `json:"tags,omitempty"`
+		hidden string
+	}
+
+	e1 := entity{Name: "one", GUID: "abc"}
+	e1.Account.ID = 1
+	e1.Tags = []tag{{Key: "env", Values: []string{"prod", "eu"}}}
+
+	e2 := entity{Name: "two, with comma", GUID: "def"}
+	e2.Account.ID = 2
+
+	header, records, err := flattenRecords([]entity{e1, e2}, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"name", "guid", "account.id", "tags.0.key", "tags.0.values.0", "tags.0.values.1"}, header)
+	assert.Equal(t, record{
+		"name":            "one",
+		"guid":            "abc",
+		"account.id":      "1",
+		"tags.0.key":      "env",
+		"tags.0.values.0": "prod",
+		"tags.0.values.1": "eu",
+	}, records[0])
+	assert.Equal(t, record{
+		"name":       "two, with comma",
+		"guid":       "def",
+		"account.id": "2",
+	}, records[1])
+}
+
+func TestFlattenRecordsMaps(t *testing.T) {
+	t.Parallel()
+
+	results := []map[string]interface{}{
+		{"count": 1234, "facet": "web", "nested": map[string]interface{}{"ok": true}},
+		{"count": 1.5, "facet": nil},
+	}
+
+	header, records, err := flattenRecords(results, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"count", "facet", "nested.ok"}, header)
+	assert.Equal(t, record{"count": "1234", "facet": "web", "nested.ok": "true"}, records[0])
+	assert.Equal(t, record{"count": "1.5", "facet": ""}, records[1])
+}
+
+func TestFlattenRecordsScalars(t *testing.T) {
+	t.Parallel()
+
+	header, records, err := flattenRecords([]string{"a", "b"}, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"value"}, header)
+	assert.Equal(t, []record{{"value": "a"}, {"value": "b"}}, records)
+
+	header, records, err = flattenRecords([]byte(`null`), true)
+	require.NoError(t, err)
+	assert.Empty(t, header)
+	assert.Empty(t, records)
+}
+
+func TestFlattenRecordsSummarizesArrays(t *testing.T) {
+	t.Parallel()
+
+	data := []map[string]interface{}{
+		{
+			"name":   "one",
+			"labels": []string{"a", "b"},
+			"tags":   []map[string]string{{"key": "env"}, {"key": "team"}},
+		},
+	}
+
+	header, records, err := flattenRecords(data, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"labels", "name", "tags"}, header)
+	assert.Equal(t, record{"labels": "a, b", "name": "one", "tags": "[2 items]"}, records[0])
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/term"
+)
+
+// clearScreen moves the cursor to the top left of the terminal and clears it
+const clearScreen = "\033[H\033[2J"
+
+var highlightColors = text.Colors{text.FgYellow, text.Bold}
+
+// SetHighlightChanges highlights the values of Text output which changed
+// since the data was last printed, such as when a query is run repeatedly.
+func SetHighlightChanges(enabled bool) (err error) {
+	if err = ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	globalOutput.highlightChanges = enabled
+	globalOutput.previousValues = nil
+
+	return nil
+}
+
+// ClearScreen clears the terminal so the next output is drawn in place of
+// the last.  Nothing is written unless the output goes to a terminal.
+func ClearScreen() (err error) {
+	if err = ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	if globalOutput.outputFile == nil && term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Fprint(globalOutput.writer, clearScreen)
+	}
+
+	return nil
+}
+
+// changes tracks the cells of a table, to highlight those which differ from
+// the table printed before
+type changes struct {
+	output  *Output
+	current map[string]string
+}
+
+func (o *Output) trackChanges() *changes {
+	return &changes{output: o, current: map[string]string{}}
+}
+
+// cell returns the value of a cell, highlighted when it changed
+func (c *changes) cell(row int, column string, value string) interface{} {
+	key := fmt.Sprintf("%d\x00%s", row, column)
+	c.current[key] = value
+
+	if !c.output.highlightChanges || c.output.noColor || c.output.previousValues == nil {
+		return value
+	}
+
+	if previous, ok := c.output.previousValues[key]; ok && previous != value {
+		return highlightColors.Sprint(value)
+	}
+
+	return value
+}
+
+// done remembers the cells, to compare the next table with
+func (c *changes) done() {
+	if c.output.highlightChanges {
+		c.output.previousValues = c.current
+	}
+}
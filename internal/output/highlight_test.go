@@ -0,0 +1,38 @@
+// +build unit
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlightChanges(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	o := &Output{terminalWidth: 80, writer: &buf, highlightChanges: true}
+
+	require.NoError(t, o.text([]map[string]interface{}{{"name": "web", "count": 1}}))
+	assert.NotContains(t, buf.String(), highlightColors.Sprint("1"))
+
+	buf.Reset()
+	require.NoError(t, o.text([]map[string]interface{}{{"name": "web", "count": 2}}))
+	assert.Contains(t, buf.String(), highlightColors.Sprint("2"))
+	assert.NotContains(t, buf.String(), highlightColors.Sprint("web"))
+
+	buf.Reset()
+	require.NoError(t, o.text([]map[string]interface{}{{"name": "web", "count": 2}}))
+	assert.NotContains(t, buf.String(), highlightColors.Sprint("2"))
+
+	// Nothing is highlighted unless asked
+	o = &Output{terminalWidth: 80, writer: &buf}
+	require.NoError(t, o.text([]map[string]interface{}{{"name": "web", "count": 1}}))
+
+	buf.Reset()
+	require.NoError(t, o.text([]map[string]interface{}{{"name": "web", "count": 2}}))
+	assert.NotContains(t, buf.String(), highlightColors.Sprint("2"))
+}
@@ -14,7 +14,7 @@ func (o *Output) jsonSetPrettyPrint(pretty bool) error {
 	}
 
 	if pretty {
-		o.jsonFormatter.DisabledColor = false
+		o.jsonFormatter.DisabledColor = o.noColor
 		o.jsonFormatter.Indent = 2
 		o.jsonFormatter.Newline = "\n"
 	} else {
@@ -59,7 +59,7 @@ func (o *Output) json(data interface{}) error {
 		return err
 	}
 
-	fmt.Println(bytes.NewBuffer(formatted).String())
+	fmt.Fprintln(o.writer, bytes.NewBuffer(formatted).String())
 
 	return nil
 }
@@ -0,0 +1,92 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// ndjson prints out data as newline delimited JSON, one compact
+// document per element.  Each element is written as soon as it is
+// encoded, so large lists and channels are streamed rather than buffered.
+func (o *Output) ndjson(data interface{}) error {
+	// Early quit on no data
+	if data == nil {
+		return nil
+	}
+
+	if o == nil {
+		return errors.New("invalid output formatter")
+	}
+
+	enc := json.NewEncoder(o.writer)
+	enc.SetEscapeHTML(false)
+
+	// Let's see what they sent us
+	switch d := data.(type) {
+	case *bytes.Buffer:
+		return ndjsonFromRaw(enc, d.Bytes())
+	case []byte:
+		return ndjsonFromRaw(enc, d)
+	case json.RawMessage:
+		return ndjsonFromRaw(enc, d)
+	}
+
+	switch v := reflect.ValueOf(data); v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := enc.Encode(v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	case reflect.Chan:
+		for {
+			elem, ok := v.Recv()
+			if !ok {
+				break
+			}
+
+			if err := enc.Encode(elem.Interface()); err != nil {
+				return err
+			}
+		}
+	default:
+		return enc.Encode(data)
+	}
+
+	return nil
+}
+
+// ndjsonFromRaw re-encodes raw JSON, emitting each element of a
+// top-level array on its own line as it is decoded.
+func ndjsonFromRaw(enc *json.Encoder, raw []byte) error {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return nil
+	}
+
+	if trimmed[0] != '[' {
+		return enc.Encode(json.RawMessage(trimmed))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+
+	// Consume the opening delimiter
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		var elem json.RawMessage
+		if err := dec.Decode(&elem); err != nil {
+			return err
+		}
+
+		if err := enc.Encode(elem); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
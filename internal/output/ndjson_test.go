@@ -0,0 +1,31 @@
+// +build unit
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNDJSONFromRaw(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+
+	raw := []byte(`[
+  {"name": "one", "count": 1},
+  {"name": "two", "nested": {"ok": true}}
+]`)
+
+	require.NoError(t, ndjsonFromRaw(enc, raw))
+	assert.Equal(t, "{\"name\":\"one\",\"count\":1}\n{\"name\":\"two\",\"nested\":{\"ok\":true}}\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, ndjsonFromRaw(enc, []byte(` {"single": "object"} `)))
+	assert.Equal(t, "{\"single\":\"object\"}\n", buf.String())
+}
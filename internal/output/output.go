@@ -1,7 +1,9 @@
 package output
 
 import (
+	"io"
 	"strings"
+	"text/template"
 
 	"github.com/hokaccha/go-prettyjson"
 
@@ -22,13 +24,20 @@ const (
 	FormatJSON Format = iota
 	FormatText
 	FormatYAML
-	//FormatCSV
+	FormatCSV
+	FormatTSV
+	FormatNDJSON
+	FormatTemplate
 )
 
 var formatStrings = map[Format]string{
-	FormatJSON: "JSON",
-	FormatText: "Text",
-	FormatYAML: "YAML",
+	FormatJSON:     "JSON",
+	FormatText:     "Text",
+	FormatYAML:     "YAML",
+	FormatCSV:      "CSV",
+	FormatTSV:      "TSV",
+	FormatNDJSON:   "NDJSON",
+	FormatTemplate: "Template",
 }
 
 // Output is the main ref for the output package
@@ -36,8 +45,19 @@ type Output struct {
 	format        Format
 	prettyPrint   bool
 	terminalWidth int
+	filter        string
+	columns       []string
+	sortBy        string
+	writer        io.Writer
+	noColor       bool
+	outputFile    *outputFile
+
+	// The cells of the last table printed, when highlighting changes
+	highlightChanges bool
+	previousValues   map[string]string
 
 	jsonFormatter *prettyjson.Formatter
+	template      *template.Template
 }
 
 // String returns the string value of the format name
@@ -52,8 +72,9 @@ func (f Format) String() string {
 func FormatOptions() string {
 	ret := make([]string, 0, len(formatStrings))
 
-	for _, v := range formatStrings {
-		ret = append(ret, v)
+	// Iterate in declaration order so the help text is stable
+	for f := Format(0); int(f) < len(formatStrings); f++ {
+		ret = append(ret, f.String())
 	}
 	return strings.Join(ret, ", ")
 }
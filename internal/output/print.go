@@ -12,6 +12,10 @@ func Print(data interface{}) (err error) {
 		return err
 	}
 
+	if data, err = globalOutput.applyFilter(data); err != nil {
+		return err
+	}
+
 	switch globalOutput.format {
 	case FormatJSON:
 		err = globalOutput.json(data)
@@ -19,6 +23,14 @@ func Print(data interface{}) (err error) {
 		err = globalOutput.text(data)
 	case FormatYAML:
 		err = globalOutput.yaml(data)
+	case FormatCSV:
+		err = globalOutput.csv(data)
+	case FormatTSV:
+		err = globalOutput.tsv(data)
+	case FormatNDJSON:
+		err = globalOutput.ndjson(data)
+	case FormatTemplate:
+		err = globalOutput.goTemplate(data)
 	default:
 		err = globalOutput.json(data)
 	}
@@ -39,6 +51,9 @@ func Printf(format string, a ...interface{}) {
 // explicitly print JSON to the screen
 func JSON(data interface{}) {
 	utils.LogIfFatal(ensureGlobalOutput())
+
+	data, err := globalOutput.applyFilter(data)
+	utils.LogIfFatal(err)
 	utils.LogIfFatal(globalOutput.json(data))
 }
 
@@ -46,6 +61,9 @@ func JSON(data interface{}) {
 // explicitly print text to the screen
 func Text(data interface{}) {
 	utils.LogIfFatal(ensureGlobalOutput())
+
+	data, err := globalOutput.applyFilter(data)
+	utils.LogIfFatal(err)
 	utils.LogIfFatal(globalOutput.text(data))
 }
 
@@ -53,5 +71,8 @@ func Text(data interface{}) {
 // explicitly print YAML to the screen
 func YAML(data interface{}) {
 	utils.LogIfFatal(ensureGlobalOutput())
+
+	data, err := globalOutput.applyFilter(data)
+	utils.LogIfFatal(err)
 	utils.LogIfFatal(globalOutput.yaml(data))
 }
@@ -0,0 +1,115 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the helper functions available to output templates
+var templateFuncs = template.FuncMap{
+	"join":  join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// join concatenates the elements of a list, which is a []interface{} once
+// the data has been through JSON
+func join(list interface{}, sep string) string {
+	switch l := list.(type) {
+	case []string:
+		return strings.Join(l, sep)
+	case []interface{}:
+		s := make([]string, len(l))
+		for i, v := range l {
+			s[i] = fmt.Sprint(v)
+		}
+
+		return strings.Join(s, sep)
+	case nil:
+		return ""
+	}
+
+	return fmt.Sprint(list)
+}
+
+// SetTemplate parses the Go template used by the Template output format.
+func SetTemplate(text string) (err error) {
+	if err = ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("error parsing output template: %s", err)
+	}
+
+	globalOutput.template = tmpl
+
+	return nil
+}
+
+// SetTemplateFile reads and parses the Go template used by the Template
+// output format from the specified file.
+func SetTemplateFile(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading output template file %s: %s", path, err)
+	}
+
+	return SetTemplate(string(content))
+}
+
+// goTemplate renders data using the configured Go template
+func (o *Output) goTemplate(data interface{}) error {
+	// Early quit on no data
+	if data == nil {
+		return nil
+	}
+
+	if o == nil {
+		return errors.New("invalid output formatter")
+	}
+
+	if o.template == nil {
+		return errors.New("no output template provided, use --template or --template-file")
+	}
+
+	// Go through JSON so templates see the same keys with or without --filter
+	normalized, err := normalize(data)
+	if err != nil {
+		return err
+	}
+
+	return o.template.Execute(o.writer, templateData(normalized))
+}
+
+// templateData converts the generic values produced by normalize into
+// plain maps and slices so they can be addressed by key in a template.
+func templateData(data interface{}) interface{} {
+	switch d := data.(type) {
+	case *orderedMap:
+		m := make(map[string]interface{}, len(d.keys))
+		for _, k := range d.keys {
+			m[k] = templateData(d.values[k])
+		}
+
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(d))
+		for i, v := range d {
+			s[i] = templateData(v)
+		}
+
+		return s
+	}
+
+	return data
+}
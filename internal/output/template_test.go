@@ -0,0 +1,77 @@
+// +build unit
+
+package output
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateRendering(t *testing.T) {
+	t.Parallel()
+
+	type app struct {
+		Name string
+		GUID string
+		Tags []string
+	}
+
+	tmpl, err := template.New("test").Funcs(templateFuncs).Parse(`{{range .}}{{.Name | upper}}	{{.GUID}}	{{join .Tags ","}}{{"\n"}}{{end}}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	o := &Output{writer: &buf, template: tmpl}
+	err = o.goTemplate([]app{
+		{Name: "one", GUID: "abc", Tags: []string{"a", "b"}},
+		{Name: "two", GUID: "def"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ONE\tabc\ta,b\nTWO\tdef\t\n", buf.String())
+}
+
+func TestTemplateDataFromFilter(t *testing.T) {
+	t.Parallel()
+
+	o := &Output{filter: "#.account"}
+	filtered, err := o.applyFilter([]byte(`[{"account": {"id": 1}}, {"account": {"id": 2}}]`))
+	require.NoError(t, err)
+
+	tmpl, err := template.New("test").Funcs(templateFuncs).Parse(`{{range .}}{{.id}} {{json .}};{{end}}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, templateData(filtered)))
+	assert.Equal(t, `1 {"id":1};2 {"id":2};`, buf.String())
+}
+
+func TestTemplateSameKeysWithFilter(t *testing.T) {
+	t.Parallel()
+
+	type account struct {
+		AccountID int    `json:"accountId"`
+		Name      string `json:"name"`
+	}
+
+	tmpl, err := template.New("test").Funcs(templateFuncs).Parse(`{{range .}}{{.accountId}} {{.name}};{{end}}`)
+	require.NoError(t, err)
+
+	for _, filter := range []string{"", "@this"} {
+		var buf bytes.Buffer
+		o := &Output{filter: filter, writer: &buf, template: tmpl}
+
+		data, err := o.applyFilter([]account{{AccountID: 1, Name: "one"}, {AccountID: 2, Name: "two"}})
+		require.NoError(t, err)
+
+		require.NoError(t, o.goTemplate(data))
+		assert.Equal(t, "1 one;2 two;", buf.String(), "filter %q", filter)
+	}
+}
+
+func TestSetTemplateInvalid(t *testing.T) {
+	err := SetTemplate("{{ .Name ")
+	assert.Error(t, err)
+}
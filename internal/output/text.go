@@ -3,8 +3,6 @@ package output
 import (
 	"errors"
 	"fmt"
-	"os"
-	"reflect"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
@@ -20,87 +18,127 @@ func (o *Output) text(data interface{}) error {
 		return errors.New("invalid output formatter")
 	}
 
+	// Messages are printed as is
+	if s, ok := data.(string); ok {
+		fmt.Fprintln(o.writer, s)
+		return nil
+	}
+
+	normalized, err := normalize(data)
+	if err != nil {
+		return fmt.Errorf("unable to format data type: %T: %s", data, err)
+	}
+
 	// Let's see what they sent us
-	switch v := reflect.ValueOf(data); v.Kind() {
-	case reflect.String:
-		fmt.Println(data)
-	case reflect.Slice, reflect.Struct:
-		return o.renderAsTable(data)
+	switch v := normalized.(type) {
+	case nil:
+		return nil
+	case *orderedMap:
+		return o.renderAsFieldTable(v)
+	case []interface{}:
+		// Lists of plain values are printed one per line
+		if isScalarList(v) {
+			for _, item := range v {
+				fmt.Fprintln(o.writer, scalarString(item))
+			}
+			return nil
+		}
+
+		return o.renderAsTable(v)
 	default:
-		return fmt.Errorf("unable to format data type: %T", data)
+		fmt.Fprintln(o.writer, scalarString(v))
 	}
 
 	return nil
 }
 
-func (o *Output) renderAsTable(data interface{}) error {
-	// Early quit on no data
-	if data == nil {
-		return nil
+// renderAsTable prints a list as a table with one row per item.  Nested
+// objects are flattened into dot-separated columns named after their
+// JSON fields, and nested lists are collapsed into a single cell.
+func (o *Output) renderAsTable(items []interface{}) error {
+	header, records := recordsFrom(items, false)
+
+	if err := o.sortRecords(header, records); err != nil {
+		return err
 	}
 
-	if o == nil {
-		return errors.New("invalid output formatter")
+	header, err := o.selectColumns(header)
+	if err != nil {
+		return err
 	}
 
 	tw := o.newTableWriter()
 
-	// Let's see what they sent us
-	switch v := reflect.ValueOf(data); v.Kind() {
-	case reflect.Slice:
-		// Create the header from the field names
-		typ := reflect.TypeOf(data).Elem()
-
-		cols := typ.NumField()
-		header := make([]interface{}, cols)
-		colConfig := make([]table.ColumnConfig, cols)
-
-		for i := 0; i < cols; i++ {
-			header[i] = typ.Field(i).Name
-			colConfig[i].Name = typ.Field(i).Name
-			colConfig[i].WidthMin = len(typ.Field(i).Name)
-			colConfig[i].WidthMax = o.terminalWidth * 3 / 4
-			colConfig[i].WidthMaxEnforcer = text.WrapSoft
-		}
-		tw.SetColumnConfigs(colConfig)
-		tw.AppendHeader(table.Row(header))
-
-		// Add all the rows
-		for i := 0; i < v.Len(); i++ {
-			row := make([]interface{}, v.Index(i).NumField())
-			for f := 0; f < v.Index(i).NumField(); f++ {
-				row[f] = v.Index(i).Field(f).Interface()
-			}
-			tw.AppendRow(table.Row(row))
+	row := make(table.Row, len(header))
+	colConfig := make([]table.ColumnConfig, len(header))
+
+	for i, h := range header {
+		row[i] = h
+		colConfig[i].Name = h
+		colConfig[i].WidthMin = len(h)
+		colConfig[i].WidthMax = o.terminalWidth * 3 / 4
+		colConfig[i].WidthMaxEnforcer = text.WrapSoft
+	}
+	tw.SetColumnConfigs(colConfig)
+	tw.AppendHeader(row)
+
+	// Add all the rows
+	changes := o.trackChanges()
+	for r, rec := range records {
+		row := make(table.Row, len(header))
+		for i, h := range header {
+			row[i] = changes.cell(r, h, rec[h])
 		}
+		tw.AppendRow(row)
+	}
 
-	// Single Struct becomes table view of Field | Value
-	case reflect.Struct:
-		typ := reflect.TypeOf(data)
-		tw.AppendHeader(table.Row{"Field", "Value"})
+	tw.Render()
+	changes.done()
 
-		for f := 0; f < typ.NumField(); f++ {
-			row := []interface{}{
-				typ.Field(f).Name,
-				v.Field(f).Interface(),
-			}
+	return nil
+}
 
-			tw.AppendRow(table.Row(row))
-		}
+// renderAsFieldTable prints a single object as a table view of Field | Value
+func (o *Output) renderAsFieldTable(item *orderedMap) error {
+	header, records := recordsFrom(item, false)
 
-	default:
-		return fmt.Errorf("unable to format data as table - type: %T", data)
+	header, err := o.selectColumns(header)
+	if err != nil {
+		return err
+	}
+
+	tw := o.newTableWriter()
+	tw.AppendHeader(table.Row{"Field", "Value"})
+	tw.SetColumnConfigs([]table.ColumnConfig{{
+		Name:             "Value",
+		WidthMax:         o.terminalWidth * 3 / 4,
+		WidthMaxEnforcer: text.WrapSoft,
+	}})
+
+	changes := o.trackChanges()
+	for _, h := range header {
+		tw.AppendRow(table.Row{h, changes.cell(0, h, records[0][h])})
 	}
 
 	tw.Render()
+	changes.done()
 
 	return nil
 }
 
 func (o *Output) newTableWriter() table.Writer {
 	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.SetAllowedRowLength(o.terminalWidth)
+	t.SetOutputMirror(o.writer)
+
+	// Only wrap to the terminal when we are writing to one
+	if o.outputFile == nil {
+		t.SetAllowedRowLength(o.terminalWidth)
+	}
+
+	headerColors := text.Colors{text.Bold}
+	if o.noColor {
+		headerColors = text.Colors{}
+	}
 
 	t.SetStyle(table.StyleRounded)
 	t.SetStyle(table.Style{
@@ -112,7 +150,7 @@ func (o *Output) newTableWriter() table.Writer {
 			MiddleVertical:   " ",
 		},
 		Color: table.ColorOptions{
-			Header: text.Colors{text.Bold},
+			Header: headerColors,
 		},
 		Options: table.Options{
 			DrawBorder:      false,
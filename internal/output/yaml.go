@@ -37,7 +37,7 @@ func (o *Output) yaml(data interface{}) error {
 		return err
 	}
 
-	fmt.Println(string(formatted))
+	fmt.Fprintln(o.writer, string(formatted))
 
 	return nil
 }
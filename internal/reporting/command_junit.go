@@ -18,7 +18,6 @@ import (
 const junitEventType = "TestRun"
 
 var (
-	accountID    int
 	path         string
 	dryRun       bool
 	outputEvents bool
@@ -68,7 +67,7 @@ var cmdJUnit = &cobra.Command{
 				return
 			}
 
-			if err := nrClient.Events.CreateEvent(accountID, events); err != nil {
+			if err := nrClient.Events.CreateEvent(credentials.RequireAccountID(), events); err != nil {
 				log.Fatal(err)
 			}
 
@@ -105,10 +104,9 @@ func createTestRunEvent(testRunID uuid.UUID, suite junit.Suite, test junit.Test)
 
 func init() {
 	Command.AddCommand(cmdJUnit)
-	cmdJUnit.Flags().IntVarP(&accountID, "accountId", "a", 0, "the New Relic account ID to send test run results to")
+	credentials.AddAccountIDFlag(cmdJUnit.Flags(), "the New Relic account ID to send test run results to")
 	cmdJUnit.Flags().StringVarP(&path, "path", "p", "", "the path to a JUnit-formatted test results file")
 	cmdJUnit.Flags().BoolVarP(&outputEvents, "output", "o", false, "output generated custom events to stdout")
 	cmdJUnit.Flags().BoolVar(&dryRun, "dryRun", false, "suppress posting custom events to NRDB")
-	utils.LogIfError(cmdJUnit.MarkFlagRequired("accountId"))
 	utils.LogIfError(cmdJUnit.MarkFlagRequired("path"))
 }
@@ -14,5 +14,5 @@ func TestJUnit(t *testing.T) {
 	assert.Equal(t, "junit", cmdJUnit.Name())
 
 	testcobra.CheckCobraMetadata(t, cmdJUnit)
-	testcobra.CheckCobraRequiredFlags(t, cmdJUnit, []string{"accountId", "path"})
+	testcobra.CheckCobraRequiredFlags(t, cmdJUnit, []string{"path"})
 }
@@ -0,0 +1,86 @@
+package undo
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+)
+
+var (
+	listOperations bool
+)
+
+// operationSummary is an operation in the journal without its saved state
+type operationSummary struct {
+	ID          string     `json:"id" yaml:"id"`
+	Time        time.Time  `json:"time" yaml:"time"`
+	Description string     `json:"description" yaml:"description"`
+	UndoneAt    *time.Time `json:"undoneAt,omitempty" yaml:"undoneAt,omitempty"`
+}
+
+// Command represents the undo command
+var Command = &cobra.Command{
+	Use:   "undo [operation-id]",
+	Short: "Undo a change to entity tags or NerdStorage",
+	Long: `Undo a change to entity tags or NerdStorage
+
+Before entity tags are deleted or replaced, or NerdStorage documents and
+collections are written or deleted, their previous state is saved to a journal
+in the config directory.  The undo command restores the state saved by the
+given operation, or by the latest operation which hasn't been undone.  Use
+--list to see the operations which can be undone.
+`,
+	Example: `newrelic undo --list
+newrelic undo 20210115T093012-4f2a`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := journalDir()
+
+		if listOperations {
+			ops, err := list(dir)
+			utils.LogIfFatal(err)
+
+			summaries := []operationSummary{}
+			for _, op := range ops {
+				summaries = append(summaries, operationSummary{ID: op.ID, Time: op.Time, Description: op.Description, UndoneAt: op.UndoneAt})
+			}
+
+			utils.LogIfFatal(output.Print(summaries))
+			return
+		}
+
+		var op *Operation
+		var err error
+
+		if len(args) > 0 {
+			op, err = load(dir, args[0])
+		} else {
+			op, err = latest(dir)
+		}
+		utils.LogIfFatal(err)
+
+		if op.UndoneAt != nil {
+			log.Fatalf("operation %s was already undone at %s", op.ID, op.UndoneAt.Format(time.RFC3339))
+		}
+
+		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			utils.LogIfFatal(restore(nrClient, op))
+
+			if !client.DryRun() {
+				utils.LogIfFatal(markUndone(dir, op))
+			}
+
+			log.Infof("undid %s", op.Description)
+		})
+	},
+}
+
+func init() {
+	Command.Flags().BoolVar(&listOperations, "list", false, "list the operations which can be undone, newest first")
+}
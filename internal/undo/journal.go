@@ -0,0 +1,148 @@
+package undo
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+)
+
+// DefaultJournalDirectory is the name of the directory, within the config
+// directory, holding the journal of operations which can be undone
+const DefaultJournalDirectory = "undo"
+
+// Kinds of operation which can be undone
+const (
+	KindEntityTags            = "entityTags"
+	KindNerdStorageDocument   = "nerdStorageDocument"
+	KindNerdStorageCollection = "nerdStorageCollection"
+)
+
+// Operation records the state of New Relic data before a command changed
+// it, so the change can be undone.
+type Operation struct {
+	ID          string     `json:"id" yaml:"id"`
+	Time        time.Time  `json:"time" yaml:"time"`
+	Kind        string     `json:"kind" yaml:"kind"`
+	Description string     `json:"description" yaml:"description"`
+	UndoneAt    *time.Time `json:"undoneAt,omitempty" yaml:"undoneAt,omitempty"`
+
+	Tags      *TagsSnapshot      `json:"tags,omitempty" yaml:"-"`
+	Documents *DocumentsSnapshot `json:"documents,omitempty" yaml:"-"`
+}
+
+// journalDir returns the directory of the journal in the config directory
+func journalDir() string {
+	return filepath.Join(config.DefaultConfigDirectory, DefaultJournalDirectory)
+}
+
+// record writes an operation to the journal in dir, giving it an ID
+func record(dir string, op *Operation) error {
+	suffix := make([]byte, 2)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+
+	op.Time = time.Now().UTC()
+	op.ID = op.Time.Format("20060102T150405") + "-" + hex.EncodeToString(suffix)
+
+	return write(dir, op)
+}
+
+func write(dir string, op *Operation) error {
+	content, err := json.MarshalIndent(op, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, op.ID+".json"), content, 0600)
+}
+
+// list returns the operations in the journal in dir, newest first
+func list(dir string) ([]*Operation, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []*Operation{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	ops := []*Operation{}
+
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+
+		op, err := load(dir, strings.TrimSuffix(f.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+
+		ops = append(ops, op)
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].ID > ops[j].ID
+	})
+
+	return ops, nil
+}
+
+func load(dir string, id string) (*Operation, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, filepath.Base(id)+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no operation with ID %s, use --list to see the operations which can be undone", id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	op := &Operation{}
+	if err = json.Unmarshal(content, op); err != nil {
+		return nil, fmt.Errorf("error reading operation %s: %s", id, err)
+	}
+
+	return op, nil
+}
+
+// latest returns the newest operation in the journal which hasn't been
+// undone
+func latest(dir string) (*Operation, error) {
+	ops, err := list(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range ops {
+		if op.UndoneAt == nil {
+			return op, nil
+		}
+	}
+
+	return nil, fmt.Errorf("there are no operations to undo")
+}
+
+// markUndone records that an operation has been undone, so it isn't undone
+// again
+func markUndone(dir string, op *Operation) error {
+	now := time.Now().UTC()
+	op.UndoneAt = &now
+
+	return write(dir, op)
+}
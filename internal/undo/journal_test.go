@@ -0,0 +1,151 @@
+// +build unit
+
+package undo
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+	"github.com/newrelic/newrelic-client-go/pkg/entities"
+)
+
+func TestUndoCommand(t *testing.T) {
+	assert.Equal(t, "undo", Command.Name())
+
+	testcobra.CheckCobraMetadata(t, Command)
+	testcobra.CheckCobraRequiredFlags(t, Command, []string{})
+}
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-undo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// An empty journal has nothing to undo
+	ops, err := list(dir)
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+
+	_, err = latest(dir)
+	assert.Error(t, err)
+
+	first := &Operation{
+		Kind:        KindEntityTags,
+		Description: "replace tags",
+		Tags:        &TagsSnapshot{GUID: "MTIz", Tags: []*entities.EntityTag{{Key: "env", Values: []string{"prod"}}}},
+	}
+	require.NoError(t, record(dir, first))
+	assert.NotEmpty(t, first.ID)
+
+	second := &Operation{
+		Kind:        KindNerdStorageDocument,
+		Description: "delete document",
+		Documents:   &DocumentsSnapshot{Scope: "USER", Collection: "c", DocumentID: "d", Documents: []Document{{ID: "d", Document: map[string]interface{}{"a": "b"}}}},
+	}
+	require.NoError(t, record(dir, second))
+
+	ops, err = list(dir)
+	require.NoError(t, err)
+	require.Len(t, ops, 2)
+
+	loaded, err := load(dir, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.Tags, loaded.Tags)
+	assert.Nil(t, loaded.UndoneAt)
+
+	require.NoError(t, markUndone(dir, loaded))
+
+	loaded, err = load(dir, first.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, loaded.UndoneAt)
+
+	next, err := latest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, next.ID)
+	assert.Equal(t, second.Documents.Documents, next.Documents.Documents)
+
+	require.NoError(t, markUndone(dir, next))
+
+	_, err = latest(dir)
+	assert.Error(t, err)
+
+	_, err = load(dir, "missing")
+	assert.Error(t, err)
+}
+
+func TestSnapshotRecordedAfterChange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-cli-undo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	configDir := config.DefaultConfigDirectory
+	config.DefaultConfigDirectory = dir
+	defer func() { config.DefaultConfigDirectory = configDir }()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"actor":{"entity":{"tags":[{"key":"env","values":["prod"]}]}}}}`))
+	}))
+	defer srv.Close()
+
+	os.Setenv("NEW_RELIC_NERDGRAPH_URL", srv.URL)
+	defer os.Unsetenv("NEW_RELIC_NERDGRAPH_URL")
+
+	nrClient, err := client.CreateNRClientForProfile(&config.Config{LogLevel: "error"}, &credentials.Profile{APIKey: "apiKey"}, "US")
+	require.NoError(t, err)
+
+	// Taking a snapshot leaves the journal alone until the change succeeds
+	op, err := SnapshotTags(nrClient, "MTIz", "replace tags")
+	require.NoError(t, err)
+
+	ops, err := list(journalDir())
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+
+	require.NoError(t, Record(op))
+
+	ops, err = list(journalDir())
+	require.NoError(t, err)
+	require.Len(t, ops, 1)
+	assert.Equal(t, []*entities.EntityTag{{Key: "env", Values: []string{"prod"}}}, ops[0].Tags.Tags)
+
+	// Dry runs save nothing
+	require.NoError(t, Record(nil))
+}
+
+func TestTagChanges(t *testing.T) {
+	current := []*entities.EntityTag{
+		{Key: "account", Values: []string{"A"}},
+		{Key: "env", Values: []string{"staging"}},
+		{Key: "added", Values: []string{"x"}},
+		{Key: "team", Values: []string{"b", "a"}},
+	}
+
+	previous := []*entities.EntityTag{
+		{Key: "account", Values: []string{"A"}},
+		{Key: "env", Values: []string{"prod"}},
+		{Key: "removed", Values: []string{"y", "z"}},
+		{Key: "team", Values: []string{"a", "b"}},
+	}
+
+	deleteKeys, add := tagChanges(current, previous)
+
+	assert.Equal(t, []string{"added", "env"}, deleteKeys)
+	assert.Equal(t, []entities.TaggingTagInput{
+		{Key: "env", Values: []string{"prod"}},
+		{Key: "removed", Values: []string{"y", "z"}},
+	}, add)
+
+	deleteKeys, add = tagChanges(previous, previous)
+	assert.Empty(t, deleteKeys)
+	assert.Empty(t, add)
+}
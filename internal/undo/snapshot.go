@@ -0,0 +1,336 @@
+package undo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+	"github.com/newrelic/newrelic-client-go/pkg/entities"
+	"github.com/newrelic/newrelic-client-go/pkg/nerdstorage"
+)
+
+var errInvalidScope = errors.New("scope must be one of ACCOUNT, ENTITY, or USER")
+
+// TagsSnapshot holds the tags of an entity before they were changed
+type TagsSnapshot struct {
+	GUID entities.EntityGUID   `json:"guid"`
+	Tags []*entities.EntityTag `json:"tags"`
+}
+
+// DocumentsSnapshot holds a NerdStorage document, or the documents of a
+// collection, before they were changed.  No documents are held for a
+// document which didn't exist.
+type DocumentsSnapshot struct {
+	Scope      string     `json:"scope"`
+	AccountID  int        `json:"accountId,omitempty"`
+	EntityGUID string     `json:"entityGuid,omitempty"`
+	PackageID  string     `json:"packageId"`
+	Collection string     `json:"collection"`
+	DocumentID string     `json:"documentId,omitempty"`
+	Documents  []Document `json:"documents"`
+}
+
+// Document is a NerdStorage document and its ID
+type Document struct {
+	ID       string      `json:"id"`
+	Document interface{} `json:"document"`
+}
+
+// SnapshotTags saves the tags of an entity before they are changed.  The
+// operation returned is added to the journal with Record once the change
+// succeeds.  Nothing is saved for a dry run.
+func SnapshotTags(nrClient *newrelic.NewRelic, guid entities.EntityGUID, description string) (*Operation, error) {
+	if client.DryRun() {
+		return nil, nil
+	}
+
+	tags, err := nrClient.Entities.GetTagsForEntity(guid)
+	if err != nil {
+		return nil, fmt.Errorf("error saving the tags to undo: %s", err)
+	}
+
+	return &Operation{
+		Kind:        KindEntityTags,
+		Description: description,
+		Tags:        &TagsSnapshot{GUID: guid, Tags: tags},
+	}, nil
+}
+
+// SnapshotDocument saves a NerdStorage document before it is changed.  The
+// operation returned is added to the journal with Record once the change
+// succeeds.  Nothing is saved for a dry run.
+func SnapshotDocument(nrClient *newrelic.NewRelic, s *DocumentsSnapshot, description string) (*Operation, error) {
+	if client.DryRun() {
+		return nil, nil
+	}
+
+	doc, err := s.getDocument(&nrClient.NerdStorage)
+	if err != nil {
+		return nil, fmt.Errorf("error saving the document to undo: %s", err)
+	}
+
+	s.Documents = []Document{}
+	if doc != nil {
+		s.Documents = append(s.Documents, Document{ID: s.DocumentID, Document: doc})
+	}
+
+	return &Operation{
+		Kind:        KindNerdStorageDocument,
+		Description: description,
+		Documents:   s,
+	}, nil
+}
+
+// SnapshotCollection saves the documents of a NerdStorage collection before
+// it is deleted.  The operation returned is added to the journal with Record
+// once the change succeeds.  Nothing is saved for a dry run.
+func SnapshotCollection(nrClient *newrelic.NewRelic, s *DocumentsSnapshot, description string) (*Operation, error) {
+	if client.DryRun() {
+		return nil, nil
+	}
+
+	items, err := s.getCollection(&nrClient.NerdStorage)
+	if err != nil {
+		return nil, fmt.Errorf("error saving the collection to undo: %s", err)
+	}
+
+	s.Documents = []Document{}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		s.Documents = append(s.Documents, Document{ID: fmt.Sprint(m["id"]), Document: m["document"]})
+	}
+
+	return &Operation{
+		Kind:        KindNerdStorageCollection,
+		Description: description,
+		Documents:   s,
+	}, nil
+}
+
+// Record adds an operation saved before a change to the journal, once the
+// change has succeeded.  A nil operation, from a dry run, is ignored.
+func Record(op *Operation) error {
+	if op == nil {
+		return nil
+	}
+
+	if err := record(journalDir(), op); err != nil {
+		return fmt.Errorf("error writing the undo journal: %s", err)
+	}
+
+	log.Infof("saved the previous state, use 'newrelic undo %s' to restore it", op.ID)
+
+	return nil
+}
+
+// restore returns the data changed by an operation to its saved state
+func restore(nrClient *newrelic.NewRelic, op *Operation) error {
+	switch {
+	case op.Kind == KindEntityTags && op.Tags != nil:
+		return op.Tags.restore(&nrClient.Entities)
+	case op.Kind == KindNerdStorageDocument && op.Documents != nil:
+		return op.Documents.restoreDocument(&nrClient.NerdStorage)
+	case op.Kind == KindNerdStorageCollection && op.Documents != nil:
+		return op.Documents.restoreCollection(&nrClient.NerdStorage)
+	}
+
+	return fmt.Errorf("unable to undo operation %s of kind %s", op.ID, op.Kind)
+}
+
+// restore changes only the tags which differ from the snapshot, leaving
+// the tags New Relic manages alone.
+func (s *TagsSnapshot) restore(e *entities.Entities) error {
+	current, err := e.GetTagsForEntity(s.GUID)
+	if err != nil {
+		return err
+	}
+
+	deleteKeys, add := tagChanges(current, s.Tags)
+
+	if len(deleteKeys) > 0 {
+		result, err := e.TaggingDeleteTagFromEntity(s.GUID, deleteKeys)
+		if err = taggingError(result, err); err != nil {
+			return err
+		}
+	}
+
+	if len(add) > 0 {
+		result, err := e.TaggingAddTagsToEntity(s.GUID, add)
+		if err = taggingError(result, err); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tagChanges returns the keys to delete, and the tags to add, to change the
+// current tags back to the previous ones.
+func tagChanges(current []*entities.EntityTag, previous []*entities.EntityTag) ([]string, []entities.TaggingTagInput) {
+	currentValues := tagValues(current)
+	previousValues := tagValues(previous)
+
+	deleteKeys := []string{}
+	add := []entities.TaggingTagInput{}
+
+	for key, values := range currentValues {
+		if prev, ok := previousValues[key]; !ok || prev != values {
+			deleteKeys = append(deleteKeys, key)
+		}
+	}
+
+	for _, t := range previous {
+		if currentValues[t.Key] != previousValues[t.Key] {
+			add = append(add, entities.TaggingTagInput{Key: t.Key, Values: t.Values})
+		}
+	}
+
+	sort.Strings(deleteKeys)
+	sort.Slice(add, func(i, j int) bool {
+		return add[i].Key < add[j].Key
+	})
+
+	return deleteKeys, add
+}
+
+// tagValues returns the sorted values of each tag key, for comparison
+func tagValues(tags []*entities.EntityTag) map[string]string {
+	values := map[string]string{}
+
+	for _, t := range tags {
+		v := append([]string{}, t.Values...)
+		sort.Strings(v)
+		values[t.Key] = strings.Join(v, "\x00")
+	}
+
+	return values
+}
+
+func taggingError(result *entities.TaggingMutationResult, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if result != nil && len(result.Errors) > 0 {
+		var messages []string
+		for _, e := range result.Errors {
+			messages = append(messages, e.Message)
+		}
+
+		return errors.New(strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+func (s *DocumentsSnapshot) restoreDocument(ns *nerdstorage.NerdStorage) error {
+	if len(s.Documents) == 0 {
+		// The document was created, so remove it
+		return s.deleteDocument(ns, s.DocumentID)
+	}
+
+	return s.writeDocument(ns, s.Documents[0])
+}
+
+func (s *DocumentsSnapshot) restoreCollection(ns *nerdstorage.NerdStorage) error {
+	for _, doc := range s.Documents {
+		if err := s.writeDocument(ns, doc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *DocumentsSnapshot) getDocument(ns *nerdstorage.NerdStorage) (interface{}, error) {
+	input := nerdstorage.GetDocumentInput{
+		PackageID:  s.PackageID,
+		Collection: s.Collection,
+		DocumentID: s.DocumentID,
+	}
+
+	switch strings.ToLower(s.Scope) {
+	case "account":
+		return ns.GetDocumentWithAccountScope(s.AccountID, input)
+	case "entity":
+		return ns.GetDocumentWithEntityScope(s.EntityGUID, input)
+	case "user":
+		return ns.GetDocumentWithUserScope(input)
+	}
+
+	return nil, errInvalidScope
+}
+
+func (s *DocumentsSnapshot) getCollection(ns *nerdstorage.NerdStorage) ([]interface{}, error) {
+	input := nerdstorage.GetCollectionInput{
+		PackageID:  s.PackageID,
+		Collection: s.Collection,
+	}
+
+	switch strings.ToLower(s.Scope) {
+	case "account":
+		return ns.GetCollectionWithAccountScope(s.AccountID, input)
+	case "entity":
+		return ns.GetCollectionWithEntityScope(s.EntityGUID, input)
+	case "user":
+		return ns.GetCollectionWithUserScope(input)
+	}
+
+	return nil, errInvalidScope
+}
+
+func (s *DocumentsSnapshot) writeDocument(ns *nerdstorage.NerdStorage, doc Document) error {
+	input := nerdstorage.WriteDocumentInput{
+		PackageID:  s.PackageID,
+		Collection: s.Collection,
+		DocumentID: doc.ID,
+		Document:   doc.Document,
+	}
+
+	var err error
+
+	switch strings.ToLower(s.Scope) {
+	case "account":
+		_, err = ns.WriteDocumentWithAccountScope(s.AccountID, input)
+	case "entity":
+		_, err = ns.WriteDocumentWithEntityScope(s.EntityGUID, input)
+	case "user":
+		_, err = ns.WriteDocumentWithUserScope(input)
+	default:
+		err = errInvalidScope
+	}
+
+	return err
+}
+
+func (s *DocumentsSnapshot) deleteDocument(ns *nerdstorage.NerdStorage, id string) error {
+	input := nerdstorage.DeleteDocumentInput{
+		PackageID:  s.PackageID,
+		Collection: s.Collection,
+		DocumentID: id,
+	}
+
+	var err error
+
+	switch strings.ToLower(s.Scope) {
+	case "account":
+		_, err = ns.DeleteDocumentWithAccountScope(s.AccountID, input)
+	case "entity":
+		_, err = ns.DeleteDocumentWithEntityScope(s.EntityGUID, input)
+	case "user":
+		_, err = ns.DeleteDocumentWithUserScope(input)
+	default:
+		err = errInvalidScope
+	}
+
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/output"
 	"github.com/newrelic/newrelic-cli/internal/utils"
 	"github.com/newrelic/newrelic-client-go/newrelic"
@@ -12,7 +13,6 @@ import (
 )
 
 var (
-	accountID           int
 	name                string
 	entityGUIDs         []string
 	entitySearchQueries []string
@@ -30,7 +30,7 @@ The get command retrieves a specific workload by its account ID and workload GUI
 	Example: `newrelic workload create --accountId 12345678 --guid MjUyMDUyOHxOUjF8V09SS0xPQUR8MTI4Myt`,
 	Run: func(cmd *cobra.Command, args []string) {
 		client.WithClient(func(nrClient *newrelic.NewRelic) {
-			workload, err := nrClient.Workloads.GetWorkload(accountID, guid)
+			workload, err := nrClient.Workloads.GetWorkload(credentials.RequireAccountID(), guid)
 			utils.LogIfFatal(err)
 
 			utils.LogIfFatal(output.Print(workload))
@@ -48,7 +48,7 @@ The list command retrieves the workloads for the given account ID.
 	Example: `newrelic workload list --accountId 12345678`,
 	Run: func(cmd *cobra.Command, args []string) {
 		client.WithClient(func(nrClient *newrelic.NewRelic) {
-			workload, err := nrClient.Workloads.ListWorkloads(accountID)
+			workload, err := nrClient.Workloads.ListWorkloads(credentials.RequireAccountID())
 			utils.LogIfFatal(err)
 
 			utils.LogIfFatal(output.Print(workload))
@@ -91,7 +91,7 @@ you also have access to.
 				createInput.ScopeAccountsInput = &workloads.ScopeAccountsInput{AccountIDs: scopeAccountIDs}
 			}
 
-			workload, err := nrClient.Workloads.CreateWorkload(accountID, createInput)
+			workload, err := nrClient.Workloads.CreateWorkload(credentials.RequireAccountID(), createInput)
 			utils.LogIfFatal(err)
 
 			utils.LogIfFatal(output.Print(workload))
@@ -153,14 +153,14 @@ it to the provided account ID. An optional name can be provided for the new work
 If the name isn't specified, the name + ' copy' of the source workload is used to
 compose the new name.
 `,
-	Example: `newrelic workload duplicate --guid 'MjUyMDUyOHxBOE28QVBQTElDQVRDT058MjE1MDM3Nzk1' --accountID 12345678 --name 'New Workload'`,
+	Example: `newrelic workload duplicate --guid 'MjUyMDUyOHxBOE28QVBQTElDQVRDT058MjE1MDM3Nzk1' --accountId 12345678 --name 'New Workload'`,
 	Run: func(cmd *cobra.Command, args []string) {
 		client.WithClient(func(nrClient *newrelic.NewRelic) {
 			duplicateInput := &workloads.DuplicateInput{
 				Name: name,
 			}
 
-			workload, err := nrClient.Workloads.DuplicateWorkload(accountID, guid, duplicateInput)
+			workload, err := nrClient.Workloads.DuplicateWorkload(credentials.RequireAccountID(), guid, duplicateInput)
 			utils.LogIfFatal(err)
 
 			utils.LogIfFatal(output.Print(workload))
@@ -190,24 +190,21 @@ The delete command accepts a workload's entity GUID.
 func init() {
 	// Get
 	Command.AddCommand(cmdGet)
-	cmdGet.Flags().IntVarP(&accountID, "accountId", "a", 0, "the New Relic account ID where the workload is located")
+	credentials.AddAccountIDFlag(cmdGet.Flags(), "the New Relic account ID where the workload is located")
 	cmdGet.Flags().StringVarP(&guid, "guid", "g", "", "the GUID of the workload")
-	utils.LogIfError(cmdGet.MarkFlagRequired("accountId"))
 	utils.LogIfError(cmdGet.MarkFlagRequired("guid"))
 
 	// List
 	Command.AddCommand(cmdList)
-	cmdList.Flags().IntVarP(&accountID, "accountId", "a", 0, "the New Relic account ID you want to list workloads for")
-	utils.LogIfError(cmdList.MarkFlagRequired("accountId"))
+	credentials.AddAccountIDFlag(cmdList.Flags(), "the New Relic account ID you want to list workloads for")
 
 	// Create
 	Command.AddCommand(cmdCreate)
-	cmdCreate.Flags().IntVarP(&accountID, "accountId", "a", 0, "the New Relic account ID where you want to create the workload")
+	credentials.AddAccountIDFlag(cmdCreate.Flags(), "the New Relic account ID where you want to create the workload")
 	cmdCreate.Flags().StringVarP(&name, "name", "n", "", "the name of the workload")
 	cmdCreate.Flags().StringSliceVarP(&entityGUIDs, "entityGuid", "e", []string{}, "the list of entity Guids composing the workload")
 	cmdCreate.Flags().StringSliceVarP(&entitySearchQueries, "entitySearchQuery", "q", []string{}, "a list of search queries, combined using an OR operator")
 	cmdCreate.Flags().IntSliceVarP(&scopeAccountIDs, "scopeAccountIds", "s", []int{}, "accounts that will be used to get entities from")
-	utils.LogIfError(cmdCreate.MarkFlagRequired("accountId"))
 	utils.LogIfError(cmdCreate.MarkFlagRequired("name"))
 
 	// Update
@@ -222,9 +219,8 @@ func init() {
 	// Duplicate
 	Command.AddCommand(cmdDuplicate)
 	cmdDuplicate.Flags().StringVarP(&guid, "guid", "g", "", "the GUID of the workload you want to duplicate")
-	cmdDuplicate.Flags().IntVarP(&accountID, "accountId", "a", 0, "the New Relic Account ID where you want to create the new workload")
+	credentials.AddAccountIDFlag(cmdDuplicate.Flags(), "the New Relic Account ID where you want to create the new workload")
 	cmdDuplicate.Flags().StringVarP(&name, "name", "n", "", "the name of the workload to duplicate")
-	utils.LogIfError(cmdDuplicate.MarkFlagRequired("accountId"))
 	utils.LogIfError(cmdDuplicate.MarkFlagRequired("guid"))
 
 	// Delete